@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/klass-lk/ginboot-cli/internal/generator"
+	"github.com/klass-lk/ginboot-cli/internal/manifest"
+	"github.com/spf13/cobra"
+)
+
+var (
+	addForce   bool
+	addOffline bool
+)
+
+var addCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Add a feature to an existing Ginboot project",
+	Long: `Add storage, deployment, telemetry or database support to an existing project.
+
+Files are re-rendered from the same templates used by 'ginboot new'. Files that were
+edited by hand since they were generated are not overwritten; a diff is shown instead
+unless --force is given. New requirements are merged into go.mod.`,
+}
+
+var addStorageCmd = &cobra.Command{
+	Use:       "storage [s3]",
+	Short:     "Add file storage support",
+	Args:      cobra.ExactValidArgs(1),
+	ValidArgs: []string{"s3"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return addFeature(cmd.Context(), cmd.OutOrStdout(), func(m *manifest.Manifest) error {
+			if m.Storage == args[0] {
+				return fmt.Errorf("project already uses %s storage", args[0])
+			}
+			m.Storage = args[0]
+			return nil
+		})
+	},
+}
+
+var addDeployCmd = &cobra.Command{
+	Use:       "deploy [lambda]",
+	Short:     "Add a deployment target",
+	Args:      cobra.ExactValidArgs(1),
+	ValidArgs: []string{"lambda"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return addFeature(cmd.Context(), cmd.OutOrStdout(), func(m *manifest.Manifest) error {
+			if m.Deploy == args[0] {
+				return fmt.Errorf("project already deploys to %s", args[0])
+			}
+			m.Deploy = args[0]
+			return nil
+		})
+	},
+}
+
+var addTelemetryCmd = &cobra.Command{
+	Use:   "telemetry",
+	Short: "Add OpenTelemetry support",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return addFeature(cmd.Context(), cmd.OutOrStdout(), func(m *manifest.Manifest) error {
+			if m.Telemetry {
+				return fmt.Errorf("project already has telemetry enabled")
+			}
+			m.Telemetry = true
+			return nil
+		})
+	},
+}
+
+var addDBCmd = &cobra.Command{
+	Use:       "db [mongodb|postgres|mysql|dynamodb]",
+	Short:     "Add a database integration",
+	Args:      cobra.ExactValidArgs(1),
+	ValidArgs: []string{"mongodb", "postgres", "mysql", "dynamodb"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return addFeature(cmd.Context(), cmd.OutOrStdout(), func(m *manifest.Manifest) error {
+			if m.Database == args[0] {
+				return fmt.Errorf("project already uses %s", args[0])
+			}
+			m.Database = args[0]
+			return nil
+		})
+	},
+}
+
+// addFeature re-renders the project with the options changed by apply and
+// writes the files affected by the change.
+func addFeature(ctx context.Context, out io.Writer, apply func(m *manifest.Manifest) error) error {
+	project, err := loadProject()
+	if err != nil {
+		return err
+	}
+
+	updated := *project
+	if err := apply(&updated); err != nil {
+		return err
+	}
+
+	gen, err := projectGenerator(ctx, &updated, addOffline)
+	if err != nil {
+		return err
+	}
+	changes, err := gen.PlanUpdate(project)
+	if err != nil {
+		return err
+	}
+
+	conflicts := 0
+	for _, change := range changes {
+		if change.Action != generator.ActionConflict {
+			continue
+		}
+		conflicts++
+		fmt.Fprintf(out, "⚠️  %s was modified since it was generated:\n", change.File.Path)
+		printDiff(out, change)
+	}
+	if conflicts > 0 && !addForce {
+		return fmt.Errorf("❌ refusing to overwrite %d hand-edited file(s); re-run with --force to overwrite them", conflicts)
+	}
+
+	if err := gen.ApplyUpdate(project, changes, addForce); err != nil {
+		return fmt.Errorf("failed to update project: %w", err)
+	}
+
+	for _, change := range changes {
+		switch change.Action {
+		case generator.ActionCreate:
+			fmt.Fprintf(out, "  ✚ created %s\n", change.File.Path)
+		case generator.ActionMerge:
+			fmt.Fprintf(out, "  ⇄ merged  %s\n", change.File.Path)
+		default:
+			fmt.Fprintf(out, "  ✎ updated %s\n", change.File.Path)
+		}
+	}
+
+	fmt.Fprintf(out, "✨ Updated %s (Database: %s, Storage: %s, Deploy: %s, Telemetry: %t)\n",
+		updated.ProjectName, updated.Database, updated.Storage, updated.Deploy, updated.Telemetry)
+	fmt.Fprintln(out, "\n📝 Next steps:")
+	fmt.Fprintln(out, "  go mod tidy")
+
+	return nil
+}
+
+func init() {
+	addCmd.PersistentFlags().BoolVar(&addForce, "force", false, "Overwrite files that were edited by hand")
+	addCmd.PersistentFlags().BoolVar(&addOffline, "offline", false, "Never access the network; use the cached checkout of the project's template pack")
+	addCmd.AddCommand(addStorageCmd)
+	addCmd.AddCommand(addDeployCmd)
+	addCmd.AddCommand(addTelemetryCmd)
+	addCmd.AddCommand(addDBCmd)
+}
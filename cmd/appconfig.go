@@ -0,0 +1,261 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// DeployConfig holds the settings of one deployment.
+type DeployConfig struct {
+	StackName          string            `yaml:"stack_name,omitempty"`
+	Region             string            `yaml:"region,omitempty"`
+	UseDefaultBucket   bool              `yaml:"use_default_bucket,omitempty"`
+	S3Bucket           string            `yaml:"s3_bucket,omitempty"`
+	ParameterOverrides map[string]string `yaml:"parameter_overrides,omitempty"` // Values may reference ${ENV_VAR}
+	Tags               map[string]string `yaml:"tags,omitempty"`
+	Env                map[string]string `yaml:"env,omitempty"` // Environment of the sam process, e.g. AWS_PROFILE
+}
+
+// AppConfig is the content of ginboot-app.yml. The top-level settings are
+// shared by every environment; files written before environments existed
+// only have those. Each named environment overrides them:
+//
+//	region: eu-west-1
+//	use_default_bucket: true
+//	default_environment: dev
+//	environments:
+//	  dev:
+//	    stack_name: orders-dev
+//	  prod:
+//	    stack_name: orders-prod
+//	    tags:
+//	      cost-center: "1234"
+type AppConfig struct {
+	DeployConfig       `yaml:",inline"`
+	DefaultEnvironment string                  `yaml:"default_environment,omitempty"`
+	Environments       map[string]DeployConfig `yaml:"environments,omitempty"`
+}
+
+// Environment returns the settings of the named environment merged over the
+// shared settings, and whether the environment is defined. An empty name
+// selects DefaultEnvironment, or just the shared settings when none is set.
+func (c *AppConfig) Environment(name string) (DeployConfig, bool) {
+	if name == "" {
+		name = c.DefaultEnvironment
+	}
+	if name == "" {
+		return c.DeployConfig, true
+	}
+
+	env, ok := c.Environments[name]
+	return mergeDeployConfig(c.DeployConfig, env), ok
+}
+
+// EnvironmentNames returns the defined environments, sorted.
+func (c *AppConfig) EnvironmentNames() []string {
+	names := make([]string, 0, len(c.Environments))
+	for name := range c.Environments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// mergeDeployConfig returns base with the settings of override applied.
+func mergeDeployConfig(base, override DeployConfig) DeployConfig {
+	merged := base
+	if override.StackName != "" {
+		merged.StackName = override.StackName
+	}
+	if override.Region != "" {
+		merged.Region = override.Region
+	}
+	if override.S3Bucket != "" {
+		merged.UseDefaultBucket = false
+		merged.S3Bucket = override.S3Bucket
+	} else if override.UseDefaultBucket {
+		merged.UseDefaultBucket = true
+		merged.S3Bucket = ""
+	}
+	merged.ParameterOverrides = mergeMaps(base.ParameterOverrides, override.ParameterOverrides)
+	merged.Tags = mergeMaps(base.Tags, override.Tags)
+	merged.Env = mergeMaps(base.Env, override.Env)
+	return merged
+}
+
+// overridesOf returns the settings of config that differ from base, so that
+// mergeDeployConfig(base, overridesOf(base, config)) equals config.
+func overridesOf(base, config DeployConfig) DeployConfig {
+	var override DeployConfig
+	if config.StackName != base.StackName {
+		override.StackName = config.StackName
+	}
+	if config.Region != base.Region {
+		override.Region = config.Region
+	}
+	if config.UseDefaultBucket != base.UseDefaultBucket || config.S3Bucket != base.S3Bucket {
+		override.UseDefaultBucket = config.UseDefaultBucket
+		override.S3Bucket = config.S3Bucket
+	}
+	override.ParameterOverrides = changedValues(base.ParameterOverrides, config.ParameterOverrides)
+	override.Tags = changedValues(base.Tags, config.Tags)
+	override.Env = changedValues(base.Env, config.Env)
+	return override
+}
+
+func changedValues(base, m map[string]string) map[string]string {
+	var changed map[string]string
+	for k, v := range m {
+		if old, ok := base[k]; !ok || old != v {
+			if changed == nil {
+				changed = map[string]string{}
+			}
+			changed[k] = v
+		}
+	}
+	return changed
+}
+
+func mergeMaps(base, override map[string]string) map[string]string {
+	if len(base) == 0 && len(override) == 0 {
+		return nil
+	}
+	merged := make(map[string]string, len(base)+len(override))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range override {
+		merged[k] = v
+	}
+	return merged
+}
+
+var envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// expandEnv returns config with ${VAR} and ${VAR:-default} references in
+// its parameter overrides, tags and env replaced by environment variables.
+// References to unset variables without a default are reported together.
+func expandEnv(config DeployConfig) (DeployConfig, error) {
+	var missing []string
+	expand := func(section string, m map[string]string) map[string]string {
+		if m == nil {
+			return nil
+		}
+		expanded := make(map[string]string, len(m))
+		for key, value := range m {
+			expanded[key] = envReference.ReplaceAllStringFunc(value, func(ref string) string {
+				match := envReference.FindStringSubmatch(ref)
+				if v, ok := os.LookupEnv(match[1]); ok {
+					return v
+				}
+				if strings.Contains(ref, ":-") {
+					return match[2]
+				}
+				missing = append(missing, fmt.Sprintf("  %s.%s: $%s", section, key, match[1]))
+				return ""
+			})
+		}
+		return expanded
+	}
+
+	config.ParameterOverrides = expand("parameter_overrides", config.ParameterOverrides)
+	config.Tags = expand("tags", config.Tags)
+	config.Env = expand("env", config.Env)
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return config, fmt.Errorf("❌ environment variables referenced in the deploy configuration are not set:\n%s", strings.Join(missing, "\n"))
+	}
+	return config, nil
+}
+
+// keyValues returns the map as sorted KEY=VALUE pairs.
+func keyValues(m map[string]string) []string {
+	pairs := make([]string, 0, len(m))
+	for k, v := range m {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return pairs
+}
+
+// saveConfig saves the deployment configuration to path
+func saveConfig(path string, config *AppConfig) error {
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	err = os.WriteFile(path, data, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	return nil
+}
+
+// loadConfig loads the deployment configuration from path
+func loadConfig(path string) (*AppConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var config AppConfig
+	err = yaml.Unmarshal(data, &config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	return &config, nil
+}
+
+// selectEnvironment picks the deployment settings for env (or the default
+// environment) from config. It fails when the file defines environments but
+// none was chosen and there are no shared settings to fall back to.
+func selectEnvironment(config *AppConfig, env string) (DeployConfig, string, bool, error) {
+	if env == "" {
+		env = config.DefaultEnvironment
+	}
+	if env == "" && config.StackName == "" && len(config.Environments) > 0 {
+		return DeployConfig{}, "", false, fmt.Errorf("❌ choose an environment with --env (%s)", strings.Join(config.EnvironmentNames(), ", "))
+	}
+
+	settings, ok := config.Environment(env)
+	return settings, env, ok, nil
+}
+
+// deployedStack loads the settings of a stack that ginboot deploy created
+// for env, and the variables to add to the environment of AWS tools. Only
+// env references in the env section are expanded; parameter secrets needn't
+// be set.
+func deployedStack(path, env string) (*AppConfig, DeployConfig, string, []string, error) {
+	app, err := loadConfig(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, DeployConfig{}, "", nil, fmt.Errorf("❌ %s not found; nothing has been deployed from this project", path)
+	} else if err != nil {
+		return nil, DeployConfig{}, "", nil, err
+	}
+
+	config, env, known, err := selectEnvironment(app, env)
+	if err != nil {
+		return nil, config, env, nil, err
+	}
+	if !known {
+		return nil, config, env, nil, fmt.Errorf("❌ environment %s is not defined in %s (%s)", env, path, strings.Join(app.EnvironmentNames(), ", "))
+	}
+	if config.StackName == "" || config.Region == "" {
+		return nil, config, env, nil, fmt.Errorf("❌ %s does not set a stack name and region", path)
+	}
+
+	expanded, err := expandEnv(DeployConfig{Env: config.Env})
+	if err != nil {
+		return nil, config, env, nil, err
+	}
+	return app, config, env, keyValues(expanded.Env), nil
+}
@@ -1,56 +1,314 @@
 package cmd
 
 import (
-	"bytes"
+	"archive/zip"
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/klass-lk/ginboot-cli/internal/generator"
+	"github.com/klass-lk/ginboot-cli/internal/runner"
+	"github.com/klass-lk/ginboot-cli/pkg/ginboot/scaffold"
 	"github.com/spf13/cobra"
 )
 
+var (
+	buildNative  bool
+	buildArch    string
+	buildVerbose bool
+	buildOutput  string
+)
+
 var buildCmd = &cobra.Command{
 	Use:   "build",
 	Short: "Build the Ginboot project",
-	Long:  `Build the Ginboot project using SAM CLI.`,
+	Long: `Build the Ginboot project using SAM CLI.
+
+--native builds without SAM CLI: the bootstrap binary is compiled with the Go toolchain
+for Linux and packaged as bin/<project>.zip, ready to upload to Lambda. The archive is
+reproducible: it only changes when the binary does.
+
+The Lambda architecture (x86_64 or arm64) is the one the project was created with;
+--arch builds a --native zip for the other one.
+
+Each phase is reported with its elapsed time, and its progress is shown while it runs:
+with a spinner on a terminal, and otherwise every 10 seconds. --verbose streams the output
+of sam and go as it runs. --output json prints a summary of the build (artifacts, sizes,
+durations) on stdout for CI, with progress on stderr.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		// Get current directory name as project name
-		currentDir, err := os.Getwd()
+		project, err := loadProject()
 		if err != nil {
-			return fmt.Errorf("failed to get current directory: %w", err)
+			return err
 		}
-		projectName := filepath.Base(currentDir)
-
-		fmt.Printf("🚀 Building %s...\n", projectName)
+		projectName := project.ProjectName
 
-		// Check if SAM CLI is installed
-		if _, err := exec.LookPath("sam"); err != nil {
-			return fmt.Errorf("❌ SAM CLI is not installed. Please install it first: https://docs.aws.amazon.com/serverless-application-model/latest/developerguide/serverless-sam-cli-install.html")
+		if !project.HasLambda() {
+			return fmt.Errorf("❌ %s is configured for the %s deploy target; ginboot build only supports lambda projects", projectName, project.Deploy)
 		}
 
-		// Run sam build with output captured
-		var stdout, stderr bytes.Buffer
-		samCmd := exec.Command("sam", "build")
-		samCmd.Stdout = &stdout
-		samCmd.Stderr = &stderr
+		arch := project.Arch
+		if arch == "" {
+			arch = generator.DefaultArch
+		}
+		if buildArch != "" {
+			if !slices.Contains(scaffold.Archs, buildArch) {
+				return fmt.Errorf("❌ invalid architecture '%s': must be one of %s", buildArch, strings.Join(scaffold.Archs, ", "))
+			}
+			if buildArch != arch && !buildNative {
+				return fmt.Errorf("❌ template.yaml deploys %s for %s; --arch %s needs --native, or change Architectures in template.yaml and GOARCH in the Makefile", projectName, arch, buildArch)
+			}
+			arch = buildArch
+		}
 
-		if err := samCmd.Run(); err != nil {
-			// If build fails, show SAM's error output
-			fmt.Print(stderr.String())
-			return fmt.Errorf("❌ Build failed: %w", err)
+		if buildOutput != "text" && buildOutput != "json" {
+			return fmt.Errorf("❌ invalid --output format '%s': must be text or json", buildOutput)
+		}
+		out := cmd.OutOrStdout()
+		if buildOutput == "json" {
+			// The summary is the only output on stdout; progress goes to stderr
+			out = cmd.ErrOrStderr()
 		}
 
-		// Check if the build artifacts exist
-		if _, err := os.Stat(".aws-sam/build"); err != nil {
-			return fmt.Errorf("❌ Build failed: build directory not created")
+		fmt.Fprintf(out, "🚀 Building %s (%s)...\n", projectName, arch)
+
+		summary := &BuildSummary{Project: projectName, Builder: "sam", Arch: arch, Phases: []BuildPhase{}, Artifacts: []BuildArtifact{}}
+		start := time.Now()
+		if buildNative {
+			summary.Builder = "native"
+			err = nativeBuild(cmd.Context(), out, summary)
+		} else {
+			err = samBuild(cmd.Context(), out, summary)
 		}
+		summary.DurationMS = time.Since(start).Milliseconds()
 
-		fmt.Printf("✨ Successfully built %s!\n", projectName)
-		fmt.Println("🔍 Build artifacts are available in .aws-sam/build/")
-		fmt.Println("\n📝 Next steps:")
-		fmt.Println("  ginboot deploy")
+		if buildOutput == "json" {
+			summary.Success = err == nil
+			if err != nil {
+				summary.Error = strings.TrimPrefix(err.Error(), "❌ ")
+			}
+			if jsonErr := writeBuildJSON(cmd.OutOrStdout(), summary); jsonErr != nil && err == nil {
+				err = jsonErr
+			}
+		}
+		if err != nil {
+			return err
+		}
 
+		fmt.Fprintf(out, "\n✨ Successfully built %s in %s!\n", projectName, formatDuration(time.Since(start)))
+		for _, a := range summary.Artifacts {
+			if a.BinarySize > 0 {
+				fmt.Fprintf(out, "📦 %s: %s (bootstrap: %s)\n", a.Path, formatSize(a.Size), formatSize(a.BinarySize))
+			} else {
+				fmt.Fprintf(out, "📦 %s: %s\n", a.Path, formatSize(a.Size))
+			}
+		}
+		if !buildNative {
+			fmt.Fprintln(out, "\n📝 Next steps:")
+			fmt.Fprintln(out, "  ginboot deploy")
+		}
 		return nil
 	},
 }
+
+// BuildSummary is the JSON form of `ginboot build --output json`.
+type BuildSummary struct {
+	Project    string          `json:"project"`
+	Builder    string          `json:"builder"` // sam or native
+	Arch       string          `json:"arch"`
+	Success    bool            `json:"success"`
+	Error      string          `json:"error,omitempty"`
+	DurationMS int64           `json:"duration_ms"`
+	Phases     []BuildPhase    `json:"phases"`
+	Artifacts  []BuildArtifact `json:"artifacts"`
+}
+
+// BuildPhase is a timed step of a build.
+type BuildPhase struct {
+	Name       string `json:"name"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// BuildArtifact is a file produced by a build.
+type BuildArtifact struct {
+	Path       string `json:"path"`
+	Size       int64  `json:"size"`
+	BinarySize int64  `json:"binary_size,omitempty"` // Size of the bootstrap binary in a zip
+}
+
+// runPhase runs fn as a phase of the build reported to out, giving it the
+// writer for the output of the tools it runs, and records its duration in
+// the summary.
+func runPhase(out io.Writer, summary *BuildSummary, name string, fn func(output io.Writer) error) error {
+	p := startPhase(out, name, buildVerbose)
+	err := fn(p)
+	elapsed := p.end(err)
+	summary.Phases = append(summary.Phases, BuildPhase{Name: name, DurationMS: elapsed.Milliseconds()})
+	return err
+}
+
+// samBuild builds the project with sam build.
+func samBuild(ctx context.Context, out io.Writer, summary *BuildSummary) error {
+	if _, err := tools.LookPath("sam"); err != nil {
+		return fmt.Errorf("❌ SAM CLI is not installed. Please install it first: https://docs.aws.amazon.com/serverless-application-model/latest/developerguide/serverless-sam-cli-install.html\n   or build with the Go toolchain only: ginboot build --native")
+	}
+
+	err := runPhase(out, summary, "sam build", func(output io.Writer) error {
+		return tools.Run(ctx, runner.Command{Name: "sam", Args: []string{"build"}, Stdout: output, Stderr: output})
+	})
+	if err != nil {
+		return fmt.Errorf("❌ Build failed: %w", err)
+	}
+
+	// Check if the build artifacts exist
+	if _, err := os.Stat(samBuildDir); err != nil {
+		return fmt.Errorf("❌ Build failed: build directory not created")
+	}
+	return filepath.WalkDir(samBuildDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		summary.Artifacts = append(summary.Artifacts, BuildArtifact{Path: filepath.ToSlash(path), Size: info.Size()})
+		return nil
+	})
+}
+
+// samBuildDir is where sam build writes the artifacts.
+const samBuildDir = ".aws-sam/build"
+
+// writeBuildJSON writes the summary to w.
+func writeBuildJSON(w io.Writer, summary *BuildSummary) error {
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+// zipEpoch is the modification time of every entry of a native build zip,
+// the earliest time the format can represent.
+var zipEpoch = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// nativeBuild compiles the bootstrap binary for the Lambda provided runtime
+// on summary.Arch and packages it as bin/<project>.zip.
+func nativeBuild(ctx context.Context, out io.Writer, summary *BuildSummary) error {
+	if _, err := tools.LookPath("go"); err != nil {
+		return fmt.Errorf("❌ Go is not installed. Please install it first: https://go.dev/doc/install")
+	}
+
+	tmp, err := os.MkdirTemp("", "ginboot-build-*")
+	if err != nil {
+		return fmt.Errorf("❌ Build failed: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+	binary := filepath.Join(tmp, "bootstrap")
+
+	err = runPhase(out, summary, "go build", func(output io.Writer) error {
+		return tools.Run(ctx, runner.Command{
+			Name: "go",
+			// -trimpath and -buildvcs=false keep the binary independent of
+			// where and from which checkout it is built
+			Args:   []string{"build", "-trimpath", "-buildvcs=false", "-ldflags=-s -w", "-o", binary, "."},
+			Env:    []string{"GOOS=linux", "GOARCH=" + generator.GoArch(summary.Arch), "CGO_ENABLED=0"},
+			Stdout: output,
+			Stderr: output,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("❌ Build failed: %w", err)
+	}
+
+	archive := filepath.Join("bin", summary.Project+".zip")
+	err = runPhase(out, summary, "package", func(io.Writer) error {
+		return writeLambdaZip(archive, binary)
+	})
+	if err != nil {
+		return fmt.Errorf("❌ Build failed: %w", err)
+	}
+
+	binaryInfo, err := os.Stat(binary)
+	if err != nil {
+		return fmt.Errorf("❌ Build failed: %w", err)
+	}
+	archiveInfo, err := os.Stat(archive)
+	if err != nil {
+		return fmt.Errorf("❌ Build failed: %w", err)
+	}
+	summary.Artifacts = append(summary.Artifacts, BuildArtifact{Path: filepath.ToSlash(archive), Size: archiveInfo.Size(), BinarySize: binaryInfo.Size()})
+	return nil
+}
+
+// writeLambdaZip packages binary as the executable bootstrap entry of a zip
+// at path. The timestamp and permissions are fixed so that the same binary
+// always gives the same archive. The zip is written next to path and renamed
+// into place.
+func writeLambdaZip(path, binary string) error {
+	in, err := os.Open(binary)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	out, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(out.Name())
+
+	zw := zip.NewWriter(out)
+	header := &zip.FileHeader{Name: "bootstrap", Method: zip.Deflate, Modified: zipEpoch}
+	header.SetMode(0755)
+	w, err := zw.CreateHeader(header)
+	if err == nil {
+		_, err = io.Copy(w, in)
+	}
+	if err == nil {
+		err = zw.Close()
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	if err := os.Chmod(out.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(out.Name(), path)
+}
+
+// formatSize formats a byte count for humans, e.g. "4.2 MB".
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func init() {
+	buildCmd.Flags().BoolVar(&buildNative, "native", false, "Build bin/<project>.zip with the Go toolchain instead of SAM CLI")
+	buildCmd.Flags().StringVar(&buildArch, "arch", "", "Lambda architecture: x86_64, arm64 (default: the project's)")
+	buildCmd.Flags().BoolVarP(&buildVerbose, "verbose", "v", false, "Stream the output of sam and go as the build runs")
+	buildCmd.Flags().StringVarP(&buildOutput, "output", "o", "text", "Output format: text, or json for a build summary on stdout")
+}
@@ -0,0 +1,256 @@
+package cmd
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/klass-lk/ginboot-cli/internal/runner"
+)
+
+func TestBuild(t *testing.T) {
+	newProject(t, "")
+
+	rec := &runner.Recorder{
+		Handle: func(c runner.Command) error {
+			return os.MkdirAll(".aws-sam/build", 0755)
+		},
+	}
+	if err := execute(t, rec, "build"); err != nil {
+		t.Fatalf("build error = %v", err)
+	}
+	if got, want := rec.Lines(), []string{"sam build"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ran %q, want %q", got, want)
+	}
+}
+
+func TestBuildFails(t *testing.T) {
+	newProject(t, "")
+
+	rec := &runner.Recorder{
+		Handle: func(c runner.Command) error {
+			fmt.Fprintln(c.Stderr, "Build Failed")
+			return errors.New("exit status 1")
+		},
+	}
+	err := execute(t, rec, "build")
+	if err == nil || !strings.Contains(err.Error(), "Build failed") {
+		t.Errorf("build error = %v, want a build failure", err)
+	}
+}
+
+func TestBuildWithoutSam(t *testing.T) {
+	newProject(t, "")
+
+	rec := &runner.Recorder{Missing: []string{"sam"}}
+	err := execute(t, rec, "build")
+	if err == nil || !strings.Contains(err.Error(), "SAM CLI is not installed") {
+		t.Errorf("build error = %v, want SAM CLI reported missing", err)
+	}
+}
+
+func TestBuildNative(t *testing.T) {
+	newProject(t, "")
+
+	rec := &runner.Recorder{
+		Missing: []string{"sam"},
+		Handle: func(c runner.Command) error {
+			// The binary is written to the path following -o
+			for i, arg := range c.Args {
+				if arg == "-o" {
+					return os.WriteFile(c.Args[i+1], []byte("binary"), 0755)
+				}
+			}
+			return errors.New("no -o")
+		},
+	}
+	if err := execute(t, rec, "build", "--native"); err != nil {
+		t.Fatalf("build error = %v", err)
+	}
+
+	commands := rec.Commands()
+	if len(commands) != 1 || commands[0].Name != "go" {
+		t.Fatalf("ran %q, want a single go build", rec.Lines())
+	}
+	if got, want := commands[0].Env, []string{"GOOS=linux", "GOARCH=amd64", "CGO_ENABLED=0"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("env = %q, want %q", got, want)
+	}
+
+	r, err := zip.OpenReader(filepath.Join("bin", "demo.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if len(r.File) != 1 || r.File[0].Name != "bootstrap" {
+		t.Fatalf("zip entries = %v, want bootstrap only", r.File)
+	}
+}
+
+func TestWriteLambdaZip(t *testing.T) {
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "bootstrap")
+	if err := os.WriteFile(binary, []byte("binary"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	var archives [][]byte
+	for _, name := range []string{"a.zip", "b.zip"} {
+		path := filepath.Join(dir, "bin", name)
+		if err := writeLambdaZip(path, binary); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		archives = append(archives, data)
+	}
+	if !bytes.Equal(archives[0], archives[1]) {
+		t.Error("archives of the same binary differ")
+	}
+
+	r, err := zip.NewReader(bytes.NewReader(archives[0]), int64(len(archives[0])))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := r.File[0]
+	if f.Mode().Perm() != 0755 {
+		t.Errorf("bootstrap mode = %v, want 0755", f.Mode().Perm())
+	}
+	if !f.Modified.Equal(zipEpoch) {
+		t.Errorf("bootstrap modified = %v, want %v", f.Modified, zipEpoch)
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := map[int64]string{
+		512:             "512 B",
+		1536:            "1.5 KB",
+		5 * 1024 * 1024: "5.0 MB",
+	}
+	for n, want := range tests {
+		if got := formatSize(n); got != want {
+			t.Errorf("formatSize(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestBuildArch(t *testing.T) {
+	newProject(t, "")
+
+	rec := &runner.Recorder{
+		Handle: func(c runner.Command) error {
+			return os.WriteFile(c.Args[len(c.Args)-2], []byte("binary"), 0755)
+		},
+	}
+	if err := execute(t, rec, "build", "--native", "--arch", "arm64"); err != nil {
+		t.Fatalf("build error = %v", err)
+	}
+	if got := rec.Commands()[0].Env; !slices.Contains(got, "GOARCH=arm64") {
+		t.Errorf("env = %q, want GOARCH=arm64", got)
+	}
+
+	// SAM builds for the architecture in template.yaml
+	err := execute(t, &runner.Recorder{}, "build", "--arch", "arm64")
+	if err == nil || !strings.Contains(err.Error(), "--native") {
+		t.Errorf("build error = %v, want --arch rejected without --native", err)
+	}
+}
+
+func TestBuildJSON(t *testing.T) {
+	newProject(t, "")
+
+	rec := &runner.Recorder{
+		Handle: func(c runner.Command) error {
+			fmt.Fprintln(c.Stderr, "compile error")
+			return errors.New("exit status 1")
+		},
+	}
+	stdout, stderr, err := executeOutput(t, rec, "build", "--native", "--output", "json")
+	if err == nil {
+		t.Fatal("build succeeded, want the go build failure")
+	}
+
+	var summary BuildSummary
+	if err := json.Unmarshal([]byte(stdout), &summary); err != nil {
+		t.Fatalf("summary is not JSON: %v\n%s", err, stdout)
+	}
+	if !strings.Contains(stderr, "❌ go build failed") || !strings.Contains(stderr, "compile error") {
+		t.Errorf("stderr = %q, want the failed phase and its output", stderr)
+	}
+	if summary.Success || !strings.Contains(summary.Error, "Build failed") {
+		t.Errorf("summary success = %v, error = %q, want the failure", summary.Success, summary.Error)
+	}
+	if len(summary.Phases) != 1 || summary.Phases[0].Name != "go build" {
+		t.Errorf("phases = %+v, want go build", summary.Phases)
+	}
+	if summary.Builder != "native" || summary.Arch != "x86_64" {
+		t.Errorf("builder = %s, arch = %s, want native on x86_64", summary.Builder, summary.Arch)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	for _, tt := range []struct {
+		s    string
+		want string
+	}{
+		{"héllo ✓", "héllo ✓"},
+		{"0123456789", "0123456789"},
+		{"0123456789ab", "0123456..."},
+		{"héllo wörld ✓✓✓", "héllo w..."},
+		{"✓✓✓✓✓✓✓✓✓✓✓", "✓✓✓✓✓✓✓..."},
+	} {
+		got := truncate(tt.s, 10)
+		if got != tt.want || !utf8.ValidString(got) {
+			t.Errorf("truncate(%q, 10) = %q, want %q", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestPhase(t *testing.T) {
+	var quiet bytes.Buffer
+	p := startPhase(&quiet, "sam build", false)
+	fmt.Fprintln(p, "Building codeuri")
+	p.end(nil)
+	if strings.Contains(quiet.String(), "Building codeuri") {
+		t.Errorf("output of a successful phase was shown:\n%s", quiet.String())
+	}
+
+	p = startPhase(&quiet, "sam build", false)
+	fmt.Fprintln(p, "Error: boom")
+	p.end(errors.New("exit status 1"))
+	if !strings.Contains(quiet.String(), "❌ sam build failed") || !strings.Contains(quiet.String(), "Error: boom") {
+		t.Errorf("failed phase did not show its output:\n%s", quiet.String())
+	}
+
+	// Without a terminal, progress lines show the phase is running
+	saved := progressInterval
+	progressInterval = 10 * time.Millisecond
+	t.Cleanup(func() { progressInterval = saved })
+	var ci bytes.Buffer
+	p = startPhase(&ci, "sam build", false)
+	fmt.Fprintln(p, "Building codeuri")
+	time.Sleep(5 * progressInterval)
+	p.end(nil)
+	if !strings.Contains(ci.String(), "⏳ sam build ") || !strings.Contains(ci.String(), "  Building codeuri\n") {
+		t.Errorf("phase without a terminal printed no progress lines:\n%s", ci.String())
+	}
+
+	var verbose bytes.Buffer
+	p = startPhase(&verbose, "go build", true)
+	fmt.Fprintln(p, "compiling")
+	p.end(nil)
+	if !strings.Contains(verbose.String(), "compiling\n✅ go build") {
+		t.Errorf("verbose phase did not stream its output:\n%s", verbose.String())
+	}
+}
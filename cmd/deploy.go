@@ -3,61 +3,24 @@ package cmd
 import (
 	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
-	"path/filepath"
 	"strings"
 
+	"github.com/klass-lk/ginboot-cli/internal/runner"
+	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v2"
 )
 
-type DeployConfig struct {
-	StackName        string `yaml:"stack_name"`
-	Region           string `yaml:"region"`
-	UseDefaultBucket bool   `yaml:"use_default_bucket"`
-	S3Bucket         string `yaml:"s3_bucket,omitempty"`
-}
-
-// saveConfig saves the deployment configuration to ginboot-app.yml
-func saveConfig(config DeployConfig) error {
-	data, err := yaml.Marshal(config)
-	if err != nil {
-		return fmt.Errorf("failed to marshal config: %w", err)
-	}
-
-	err = os.WriteFile("ginboot-app.yml", data, 0644)
-	if err != nil {
-		return fmt.Errorf("failed to write config file: %w", err)
-	}
-
-	return nil
-}
-
-// loadConfig loads the deployment configuration from ginboot-app.yml
-func loadConfig() (*DeployConfig, error) {
-	data, err := os.ReadFile("ginboot-app.yml")
-	if err != nil {
-		return nil, err
-	}
-
-	var config DeployConfig
-	err = yaml.Unmarshal(data, &config)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
-	}
-
-	return &config, nil
-}
-
 // promptUser asks a question and returns the user's answer
-func promptUser(question string, defaultValue string) string {
+func promptUser(out io.Writer, question string, defaultValue string) string {
 	reader := bufio.NewReader(os.Stdin)
 	if defaultValue != "" {
-		fmt.Printf("%s [%s]: ", question, defaultValue)
+		fmt.Fprintf(out, "%s [%s]: ", question, defaultValue)
 	} else {
-		fmt.Printf("%s: ", question)
+		fmt.Fprintf(out, "%s: ", question)
 	}
 
 	answer, _ := reader.ReadString('\n')
@@ -69,133 +32,319 @@ func promptUser(question string, defaultValue string) string {
 	return answer
 }
 
+// stdinIsTerminal reports whether stdin is an interactive terminal; prompts
+// are never shown otherwise. Tests replace it to answer prompts.
+var stdinIsTerminal = func() bool {
+	fd := os.Stdin.Fd()
+	return isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd)
+}
+
 var (
 	stackName    string
 	region       string
 	capabilities []string
+	s3Bucket     string
+	resolveS3    bool
+	assumeYes    bool
+	noInput      bool
+	deployConfig string
+	deployEnv    string
+	deployPlan   string
+
+	parameterOverrides map[string]string
+	tags               map[string]string
 )
 
 var deployCmd = &cobra.Command{
 	Use:   "deploy",
 	Short: "Deploy the Ginboot project",
-	Long:  `Deploy the Ginboot project to AWS using SAM CLI.`,
+	Long: `Deploy the Ginboot project to AWS using SAM CLI.
+
+Settings are read from ginboot-app.yml (or --config) and can be overridden with flags.
+Missing settings are prompted for; with --no-input, or when stdin is not a terminal,
+the command fails instead and lists the flags to pass. --yes skips the confirmation.
+
+--plan creates a CloudFormation changeset without executing it, shows the resources
+it adds, modifies and removes, and asks whether to execute it. --plan=json prints the
+plan as JSON on stdout instead, e.g. for a pull request comment, and never executes it.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		// Get current directory name as project name
-		currentDir, err := os.Getwd()
+		project, err := loadProject()
 		if err != nil {
-			return fmt.Errorf("failed to get current directory: %w", err)
+			return err
 		}
-		projectName := filepath.Base(currentDir)
-
-		fmt.Printf("🚀 Deploying %s...\n\n", projectName)
+		projectName := project.ProjectName
 
-		// Check if SAM CLI is installed
-		if _, err := exec.LookPath("sam"); err != nil {
-			return fmt.Errorf("❌ SAM CLI is not installed. Please install it first: https://docs.aws.amazon.com/serverless-application-model/latest/developerguide/serverless-sam-cli-install.html")
+		if !project.HasLambda() {
+			return fmt.Errorf("❌ %s is configured for the %s deploy target; ginboot deploy only supports lambda projects", projectName, project.Deploy)
+		}
+		if s3Bucket != "" && resolveS3 {
+			return fmt.Errorf("❌ --s3-bucket and --resolve-s3 cannot be used together")
 		}
 
-		var config DeployConfig
-		var s3Args []string
+		if deployPlan != "" && deployPlan != "table" && deployPlan != "json" {
+			return fmt.Errorf("❌ invalid --plan format '%s': must be table or json", deployPlan)
+		}
+		out := cmd.OutOrStdout()
+		if deployPlan == "json" {
+			// The plan is the only output on stdout; progress goes to stderr
+			out = cmd.ErrOrStderr()
+		}
 
-		// Check if ginboot-app.yml exists
-		if existingConfig, err := loadConfig(); err != nil {
-			fmt.Println("📝 No ginboot-app.yml found. Please provide deployment details:")
+		interactive := !noInput && deployPlan != "json" && stdinIsTerminal()
 
-			// Prompt for required information if not provided via flags
-			if stackName == "" {
-				stackName = promptUser("Stack name", projectName)
-			}
-			if region == "" {
-				region = promptUser("AWS Region", "us-east-1")
-			}
+		fmt.Fprintf(out, "🚀 Deploying %s...\n\n", projectName)
 
-			// Ask about using default S3 bucket
-			useDefaultBucket := promptUser("Use default S3 bucket? (Y/n)", "Y")
-			useDefault := strings.EqualFold(useDefaultBucket, "y")
-
-			config = DeployConfig{
-				StackName:        stackName,
-				Region:           region,
-				UseDefaultBucket: useDefault,
+		// Check if SAM CLI is installed
+		if _, err := tools.LookPath("sam"); err != nil {
+			return fmt.Errorf("❌ SAM CLI is not installed. Please install it first: https://docs.aws.amazon.com/serverless-application-model/latest/developerguide/serverless-sam-cli-install.html")
+		}
+		if deployPlan != "" {
+			if _, err := tools.LookPath("aws"); err != nil {
+				return fmt.Errorf("❌ --plan needs the AWS CLI to read the changeset. Please install it first: https://docs.aws.amazon.com/cli/latest/userguide/getting-started-install.html")
 			}
+		}
 
-			if useDefault {
-				s3Args = []string{"--resolve-s3", "true"}
-				fmt.Println("ℹ️  Using SAM's default S3 bucket")
-			} else {
-				s3Bucket := promptUser("S3 bucket for deployment artifacts", "")
-				if s3Bucket == "" {
-					return fmt.Errorf("❌ S3 bucket is required when not using default bucket")
-				}
-				config.S3Bucket = s3Bucket
-				s3Args = []string{"--s3-bucket", s3Bucket}
-				fmt.Printf("ℹ️  Using custom S3 bucket: %s\n", s3Bucket)
-			}
+		config, err := resolveDeployConfig(out, projectName, interactive)
+		if err != nil {
+			return err
+		}
+		// Values are shown as configured so that secrets passed through
+		// ${ENV_VAR} references don't end up in the output
+		expanded, err := expandEnv(config)
+		if err != nil {
+			return err
+		}
 
-			// Save configuration for future use
-			if err := saveConfig(config); err != nil {
-				fmt.Printf("⚠️  Failed to save configuration: %v\n", err)
-			} else {
-				fmt.Println("💾 Configuration saved to ginboot-app.yml")
-			}
+		if config.UseDefaultBucket {
+			fmt.Fprintln(out, "ℹ️  Using SAM's default S3 bucket")
 		} else {
-			fmt.Println("📄 Using existing configuration from ginboot-app.yml")
-			config = *existingConfig
-
-			if config.UseDefaultBucket {
-				s3Args = []string{"--resolve-s3", "true"}
-				fmt.Println("ℹ️  Using SAM's default S3 bucket")
-			} else {
-				s3Args = []string{"--s3-bucket", config.S3Bucket}
-				fmt.Printf("ℹ️  Using custom S3 bucket: %s\n", config.S3Bucket)
-			}
+			fmt.Fprintf(out, "ℹ️  Using custom S3 bucket: %s\n", config.S3Bucket)
 		}
 
-		fmt.Println("\n⚙️ Deployment configuration:")
-		fmt.Printf("  Stack name: %s\n", config.StackName)
-		fmt.Printf("  Region: %s\n", config.Region)
-		fmt.Println()
+		fmt.Fprintln(out, "\n⚙️ Deployment configuration:")
+		fmt.Fprintf(out, "  Stack name: %s\n", config.StackName)
+		fmt.Fprintf(out, "  Region: %s\n", config.Region)
+		fmt.Fprintf(out, "  Capabilities: %s\n", strings.Join(capabilities, " "))
+		if len(config.ParameterOverrides) > 0 {
+			fmt.Fprintf(out, "  Parameters: %s\n", strings.Join(keyValues(config.ParameterOverrides), " "))
+		}
+		if len(config.Tags) > 0 {
+			fmt.Fprintf(out, "  Tags: %s\n", strings.Join(keyValues(config.Tags), " "))
+		}
+		fmt.Fprintln(out)
 
-		// Ask for confirmation
-		confirm := promptUser("Do you want to proceed with deployment? (y/N)", "N")
-		if !strings.EqualFold(confirm, "y") {
-			return fmt.Errorf("❌ Deployment cancelled")
+		// Ask for confirmation; a plan is confirmed once its changes are shown
+		if !assumeYes && deployPlan == "" {
+			confirm := promptUser(out, "Do you want to proceed with deployment? (y/N)", "N")
+			if !strings.EqualFold(confirm, "y") {
+				return fmt.Errorf("❌ Deployment cancelled")
+			}
 		}
 
-		// Prepare deploy command
-		deployArgs := []string{
-			"deploy",
-			"--stack-name", config.StackName,
-			"--region", config.Region,
-			"--capabilities", "CAPABILITY_IAM",
-			"--no-confirm-changeset",
+		deployArgs := samDeployArgs(expanded, capabilities, deployPlan != "")
+		env := keyValues(expanded.Env)
+		if deployPlan != "" {
+			return planDeploy(cmd.Context(), out, cmd.OutOrStdout(), projectName, config, deployArgs, env, interactive)
 		}
-		deployArgs = append(deployArgs, s3Args...)
 
 		// Run sam deploy
-		fmt.Println("\n🔨 Starting deployment...")
+		fmt.Fprintln(out, "\n🔨 Starting deployment...")
 		var stderr bytes.Buffer
-		samCmd := exec.Command("sam", deployArgs...)
-		samCmd.Stdout = os.Stdout
-		samCmd.Stderr = &stderr
-
-		err = samCmd.Run()
+		err = tools.Run(cmd.Context(), runner.Command{
+			Name:   "sam",
+			Args:   deployArgs,
+			Env:    env,
+			Stdout: out,
+			Stderr: &stderr,
+		})
 		if err != nil {
 			// Check if it's a "no changes" message
 			errOutput := stderr.String()
 			if strings.Contains(errOutput, "No changes to deploy") {
-				fmt.Printf("\n✨ Stack %s is up to date. No changes to deploy.\n", config.StackName)
+				fmt.Fprintf(out, "\n✨ Stack %s is up to date. No changes to deploy.\n", config.StackName)
+				printEndpoint(cmd.Context(), out, projectName, config, env)
 				return nil
 			}
+			fmt.Fprint(out, errOutput)
 			return fmt.Errorf("❌ Deployment failed: %w", err)
 		}
 
-		fmt.Printf("\n✨ Successfully deployed %s!\n", projectName)
+		fmt.Fprintf(out, "\n✨ Successfully deployed %s!\n", projectName)
+		printEndpoint(cmd.Context(), out, projectName, config, env)
 		return nil
 	},
 }
 
+// samDeployArgs returns the arguments of sam deploy for config, whose
+// ${ENV_VAR} references must already be expanded. With plan, the changeset
+// is created but not executed.
+func samDeployArgs(config DeployConfig, capabilities []string, plan bool) []string {
+	args := []string{
+		"deploy",
+		"--stack-name", config.StackName,
+		"--region", config.Region,
+	}
+	if plan {
+		args = append(args, "--no-execute-changeset")
+	} else {
+		args = append(args, "--no-confirm-changeset")
+	}
+	if len(capabilities) > 0 {
+		args = append(args, "--capabilities")
+		args = append(args, capabilities...)
+	}
+	if config.UseDefaultBucket {
+		args = append(args, "--resolve-s3")
+	} else {
+		args = append(args, "--s3-bucket", config.S3Bucket)
+	}
+	if len(config.ParameterOverrides) > 0 {
+		args = append(args, "--parameter-overrides")
+		args = append(args, keyValues(config.ParameterOverrides)...)
+	}
+	if len(config.Tags) > 0 {
+		args = append(args, "--tags")
+		args = append(args, keyValues(config.Tags)...)
+	}
+	return args
+}
+
+// resolveDeployConfig merges the deploy flags over the config file. Missing
+// settings are prompted for when interactive; otherwise they are reported
+// together. A config file is saved when none existed. Messages go to out.
+func resolveDeployConfig(out io.Writer, projectName string, interactive bool) (DeployConfig, error) {
+	app, err := loadConfig(deployConfig)
+	found := err == nil
+	if errors.Is(err, os.ErrNotExist) {
+		app = &AppConfig{}
+	} else if err != nil {
+		return DeployConfig{}, err
+	}
+
+	config, env, known, err := selectEnvironment(app, deployEnv)
+	if err != nil {
+		return config, err
+	}
+	switch {
+	case found && known && env != "":
+		fmt.Fprintf(out, "📄 Using environment %s from %s\n", env, deployConfig)
+	case found && known:
+		fmt.Fprintf(out, "📄 Using existing configuration from %s\n", deployConfig)
+	case interactive && env != "":
+		fmt.Fprintf(out, "📝 Environment %s is not defined in %s. Please provide deployment details:\n", env, deployConfig)
+	case interactive:
+		fmt.Fprintf(out, "📝 No %s found. Please provide deployment details:\n", deployConfig)
+	}
+
+	// Flags override the config file
+	config.ParameterOverrides = mergeMaps(config.ParameterOverrides, parameterOverrides)
+	config.Tags = mergeMaps(config.Tags, tags)
+	if stackName != "" {
+		config.StackName = stackName
+	}
+	if region != "" {
+		config.Region = region
+	}
+	if s3Bucket != "" {
+		config.UseDefaultBucket = false
+		config.S3Bucket = s3Bucket
+	}
+	if resolveS3 {
+		config.UseDefaultBucket = true
+		config.S3Bucket = ""
+	}
+	if config.Region == "" {
+		config.Region = firstNonEmpty(os.Getenv("AWS_REGION"), os.Getenv("AWS_DEFAULT_REGION"))
+	}
+	bucketSet := config.UseDefaultBucket || config.S3Bucket != ""
+
+	if !interactive {
+		var missing []string
+		if config.StackName == "" {
+			missing = append(missing, "  --stack-name    CloudFormation stack name")
+		}
+		if config.Region == "" {
+			missing = append(missing, "  --region        AWS region (or set AWS_REGION)")
+		}
+		if !bucketSet {
+			missing = append(missing, "  --s3-bucket     bucket for deployment artifacts, or --resolve-s3 to let SAM manage one")
+		}
+		if !assumeYes && deployPlan == "" {
+			missing = append(missing, "  --yes           confirm the deployment")
+		}
+		if len(missing) > 0 {
+			reason := "--no-input is set"
+			if !noInput {
+				reason = "stdin is not a terminal"
+			}
+			return config, fmt.Errorf("❌ cannot prompt for deployment settings because %s; missing:\n%s", reason, strings.Join(missing, "\n"))
+		}
+	} else {
+		// Prompt for required information if not provided via flags or config
+		if config.StackName == "" {
+			defaultStack := projectName
+			if env != "" {
+				defaultStack += "-" + env
+			}
+			config.StackName = promptUser(out, "Stack name", defaultStack)
+		}
+		if config.Region == "" {
+			config.Region = promptUser(out, "AWS Region", "us-east-1")
+		}
+		if !bucketSet {
+			// Ask about using default S3 bucket
+			useDefaultBucket := promptUser(out, "Use default S3 bucket? (Y/n)", "Y")
+			config.UseDefaultBucket = strings.EqualFold(useDefaultBucket, "y")
+			if !config.UseDefaultBucket {
+				config.S3Bucket = promptUser(out, "S3 bucket for deployment artifacts", "")
+				if config.S3Bucket == "" {
+					return config, fmt.Errorf("❌ S3 bucket is required when not using default bucket")
+				}
+			}
+		}
+	}
+
+	if !known {
+		// Save configuration for future use
+		if env == "" {
+			app.DeployConfig = config
+		} else {
+			if app.Environments == nil {
+				app.Environments = map[string]DeployConfig{}
+			}
+			app.Environments[env] = overridesOf(app.DeployConfig, config)
+		}
+		if err := saveConfig(deployConfig, app); err != nil {
+			fmt.Fprintf(out, "⚠️  Failed to save configuration: %v\n", err)
+		} else {
+			fmt.Fprintf(out, "💾 Configuration saved to %s\n", deployConfig)
+		}
+	}
+
+	return config, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
 func init() {
 	deployCmd.Flags().StringVar(&stackName, "stack-name", "", "AWS CloudFormation stack name")
 	deployCmd.Flags().StringVar(&region, "region", "", "AWS Region")
+	deployCmd.Flags().StringVar(&s3Bucket, "s3-bucket", "", "S3 bucket for deployment artifacts")
+	deployCmd.Flags().BoolVar(&resolveS3, "resolve-s3", false, "Let SAM create and manage the S3 bucket for deployment artifacts")
+	deployCmd.Flags().StringToStringVar(&parameterOverrides, "parameter-overrides", nil, "CloudFormation parameters as Key=Value, overriding ginboot-app.yml")
+	deployCmd.Flags().StringToStringVar(&tags, "tags", nil, "Stack tags as Key=Value, overriding ginboot-app.yml")
+	deployCmd.Flags().StringSliceVar(&capabilities, "capabilities", []string{"CAPABILITY_IAM"}, "CloudFormation capabilities to acknowledge")
+	deployCmd.Flags().StringVarP(&deployEnv, "env", "e", "", "Environment from ginboot-app.yml to deploy (default: default_environment)")
+	deployCmd.Flags().StringVar(&deployConfig, "config", "ginboot-app.yml", "Path of the deployment configuration file")
+	deployCmd.Flags().BoolVarP(&assumeYes, "yes", "y", false, "Deploy without asking for confirmation")
+	deployCmd.Flags().BoolVar(&noInput, "no-input", false, "Never prompt; fail if a setting is missing")
+	deployCmd.Flags().StringVar(&deployPlan, "plan", "", "Preview the changeset before executing it; --plan=json prints it as JSON without executing")
+	deployCmd.Flags().Lookup("plan").NoOptDefVal = "table"
 }
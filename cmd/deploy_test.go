@@ -0,0 +1,366 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/klass-lk/ginboot-cli/internal/runner"
+)
+
+func TestSamDeployArgs(t *testing.T) {
+	tests := []struct {
+		name         string
+		config       DeployConfig
+		capabilities []string
+		plan         bool
+		want         string
+	}{
+		{
+			name:         "default bucket",
+			config:       DeployConfig{StackName: "demo", Region: "us-east-1", UseDefaultBucket: true},
+			capabilities: []string{"CAPABILITY_IAM"},
+			want:         "deploy --stack-name demo --region us-east-1 --no-confirm-changeset --capabilities CAPABILITY_IAM --resolve-s3",
+		},
+		{
+			name:         "custom bucket",
+			config:       DeployConfig{StackName: "demo", Region: "eu-west-1", S3Bucket: "artifacts"},
+			capabilities: []string{"CAPABILITY_IAM"},
+			want:         "deploy --stack-name demo --region eu-west-1 --no-confirm-changeset --capabilities CAPABILITY_IAM --s3-bucket artifacts",
+		},
+		{
+			name:         "several capabilities",
+			config:       DeployConfig{StackName: "demo", Region: "us-east-1", UseDefaultBucket: true},
+			capabilities: []string{"CAPABILITY_IAM", "CAPABILITY_AUTO_EXPAND"},
+			want:         "deploy --stack-name demo --region us-east-1 --no-confirm-changeset --capabilities CAPABILITY_IAM CAPABILITY_AUTO_EXPAND --resolve-s3",
+		},
+		{
+			name:   "no capabilities",
+			config: DeployConfig{StackName: "demo", Region: "us-east-1", UseDefaultBucket: true},
+			want:   "deploy --stack-name demo --region us-east-1 --no-confirm-changeset --resolve-s3",
+		},
+		{
+			name: "parameters and tags",
+			config: DeployConfig{
+				StackName:          "demo",
+				Region:             "us-east-1",
+				UseDefaultBucket:   true,
+				ParameterOverrides: map[string]string{"Stage": "prod", "LogLevel": "warn"},
+				Tags:               map[string]string{"team": "core", "cost-center": "1234"},
+			},
+			capabilities: []string{"CAPABILITY_IAM"},
+			want:         "deploy --stack-name demo --region us-east-1 --no-confirm-changeset --capabilities CAPABILITY_IAM --resolve-s3 --parameter-overrides LogLevel=warn Stage=prod --tags cost-center=1234 team=core",
+		},
+		{
+			name:         "plan",
+			config:       DeployConfig{StackName: "demo", Region: "us-east-1", S3Bucket: "artifacts"},
+			capabilities: []string{"CAPABILITY_IAM"},
+			plan:         true,
+			want:         "deploy --stack-name demo --region us-east-1 --no-execute-changeset --capabilities CAPABILITY_IAM --s3-bucket artifacts",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := strings.Join(samDeployArgs(tt.config, tt.capabilities, tt.plan), " ")
+			if got != tt.want {
+				t.Errorf("samDeployArgs() =\n  %s\nwant\n  %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeploy(t *testing.T) {
+	tests := []struct {
+		name      string
+		appConfig string
+		args      []string
+		setenv    map[string]string
+		want      string
+		wantEnv   []string
+	}{
+		{
+			name:      "config file",
+			appConfig: "stack_name: demo\nregion: us-east-1\nuse_default_bucket: true\n",
+			want:      "sam deploy --stack-name demo --region us-east-1 --no-confirm-changeset --capabilities CAPABILITY_IAM --resolve-s3",
+		},
+		{
+			name:      "flags override config",
+			appConfig: "stack_name: demo\nregion: us-east-1\nuse_default_bucket: true\ntags:\n  team: core\n",
+			args:      []string{"--stack-name", "other", "--region", "eu-west-1", "--s3-bucket", "artifacts", "--tags", "owner=me", "--capabilities", "CAPABILITY_IAM,CAPABILITY_AUTO_EXPAND"},
+			want:      "sam deploy --stack-name other --region eu-west-1 --no-confirm-changeset --capabilities CAPABILITY_IAM CAPABILITY_AUTO_EXPAND --s3-bucket artifacts --tags owner=me team=core",
+		},
+		{
+			name:   "flags only",
+			args:   []string{"--stack-name", "demo", "--resolve-s3"},
+			setenv: map[string]string{"AWS_REGION": "ap-south-1"},
+			want:   "sam deploy --stack-name demo --region ap-south-1 --no-confirm-changeset --capabilities CAPABILITY_IAM --resolve-s3",
+		},
+		{
+			name: "environment",
+			appConfig: `region: us-east-1
+use_default_bucket: true
+default_environment: dev
+environments:
+  dev:
+    stack_name: demo-dev
+  prod:
+    stack_name: demo-prod
+    s3_bucket: prod-artifacts
+    parameter_overrides:
+      Stage: prod
+      DBPassword: ${DB_PASSWORD}
+      LogLevel: ${LOG_LEVEL:-warn}
+    env:
+      AWS_PROFILE: ${PROFILE}
+`,
+			args:    []string{"--env", "prod"},
+			setenv:  map[string]string{"DB_PASSWORD": "s3cret", "PROFILE": "production"},
+			want:    "sam deploy --stack-name demo-prod --region us-east-1 --no-confirm-changeset --capabilities CAPABILITY_IAM --s3-bucket prod-artifacts --parameter-overrides DBPassword=s3cret LogLevel=warn Stage=prod",
+			wantEnv: []string{"AWS_PROFILE=production"},
+		},
+		{
+			name:      "default environment",
+			appConfig: "region: us-east-1\nuse_default_bucket: true\ndefault_environment: dev\nenvironments:\n  dev:\n    stack_name: demo-dev\n",
+			want:      "sam deploy --stack-name demo-dev --region us-east-1 --no-confirm-changeset --capabilities CAPABILITY_IAM --resolve-s3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("AWS_REGION", "")
+			t.Setenv("AWS_DEFAULT_REGION", "")
+			for k, v := range tt.setenv {
+				t.Setenv(k, v)
+			}
+			newProject(t, tt.appConfig)
+
+			rec := &runner.Recorder{Missing: []string{"aws"}}
+			args := append([]string{"deploy", "--no-input", "--yes"}, tt.args...)
+			if err := execute(t, rec, args...); err != nil {
+				t.Fatalf("deploy error = %v", err)
+			}
+
+			commands := rec.Commands()
+			if len(commands) != 1 {
+				t.Fatalf("ran %q, want a single sam deploy", rec.Lines())
+			}
+			if got := commands[0].String(); got != tt.want {
+				t.Errorf("ran\n  %s\nwant\n  %s", got, tt.want)
+			}
+			if strings.Join(commands[0].Env, " ") != strings.Join(tt.wantEnv, " ") {
+				t.Errorf("env = %q, want %q", commands[0].Env, tt.wantEnv)
+			}
+		})
+	}
+}
+
+func TestDeployMissingSettings(t *testing.T) {
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_DEFAULT_REGION", "")
+	newProject(t, "")
+
+	rec := &runner.Recorder{}
+	err := execute(t, rec, "deploy", "--no-input", "--stack-name", "demo")
+	if err == nil {
+		t.Fatal("deploy succeeded without a region, bucket and --yes")
+	}
+	for _, flag := range []string{"--region", "--s3-bucket", "--yes"} {
+		if !strings.Contains(err.Error(), flag) {
+			t.Errorf("error does not mention %s:\n%v", flag, err)
+		}
+	}
+	if lines := rec.Lines(); len(lines) != 0 {
+		t.Errorf("ran %q, want nothing", lines)
+	}
+}
+
+func TestDeployMissingEnvVar(t *testing.T) {
+	newProject(t, "stack_name: demo\nregion: us-east-1\nuse_default_bucket: true\nparameter_overrides:\n  DBPassword: ${GINBOOT_TEST_UNSET}\n")
+
+	rec := &runner.Recorder{}
+	err := execute(t, rec, "deploy", "--no-input", "--yes")
+	if err == nil || !strings.Contains(err.Error(), "GINBOOT_TEST_UNSET") {
+		t.Fatalf("deploy error = %v, want the unset variable reported", err)
+	}
+	if lines := rec.Lines(); len(lines) != 0 {
+		t.Errorf("ran %q, want nothing", lines)
+	}
+}
+
+func TestDeployNoChanges(t *testing.T) {
+	newProject(t, "stack_name: demo\nregion: us-east-1\nuse_default_bucket: true\n")
+
+	rec := &runner.Recorder{
+		Missing: []string{"aws"},
+		Handle: func(c runner.Command) error {
+			fmt.Fprintln(c.Stderr, "Error: No changes to deploy. Stack demo is up to date")
+			return errors.New("exit status 1")
+		},
+	}
+	if err := execute(t, rec, "deploy", "--no-input", "--yes"); err != nil {
+		t.Errorf("deploy error = %v, want an up to date stack to succeed", err)
+	}
+}
+
+func TestDeployPlan(t *testing.T) {
+	newProject(t, "stack_name: demo\nregion: us-east-1\nuse_default_bucket: true\n")
+
+	const arn = "arn:aws:cloudformation:us-east-1:123456789012:changeSet/samcli-deploy1/abc"
+	rec := &runner.Recorder{
+		Handle: func(c runner.Command) error {
+			switch {
+			case c.Name == "sam":
+				fmt.Fprintln(c.Stdout, "Changeset created successfully. "+arn)
+			case strings.Contains(c.String(), "describe-change-set"):
+				fmt.Fprintln(c.Stdout, `{"Status":"CREATE_COMPLETE","Changes":[{"Type":"Resource","ResourceChange":{"Action":"Modify","LogicalResourceId":"demoFunction","ResourceType":"AWS::Lambda::Function","Replacement":"False"}}]}`)
+			case strings.Contains(c.String(), "--query"):
+				fmt.Fprintln(c.Stdout, "UPDATE_COMPLETE")
+			case strings.Contains(c.String(), "describe-stacks"):
+				fmt.Fprintln(c.Stdout, `{"Stacks":[{"StackStatus":"UPDATE_COMPLETE"}]}`)
+			}
+			return nil
+		},
+	}
+	if err := execute(t, rec, "deploy", "--no-input", "--plan", "--yes"); err != nil {
+		t.Fatalf("deploy --plan error = %v", err)
+	}
+
+	want := []string{
+		"sam deploy --stack-name demo --region us-east-1 --no-execute-changeset --capabilities CAPABILITY_IAM --resolve-s3",
+		"aws cloudformation describe-change-set --change-set-name " + arn + " --region us-east-1 --output json",
+		"aws cloudformation describe-stacks --stack-name demo --region us-east-1 --query Stacks[0].StackStatus --output text",
+		"aws cloudformation execute-change-set --change-set-name " + arn + " --region us-east-1",
+		"aws cloudformation wait stack-update-complete --stack-name demo --region us-east-1",
+		"aws cloudformation describe-stacks --stack-name demo --region us-east-1 --output json",
+	}
+	if got := rec.Lines(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ran\n  %s\nwant\n  %s", strings.Join(got, "\n  "), strings.Join(want, "\n  "))
+	}
+}
+
+func TestDeployPlanJSON(t *testing.T) {
+	newProject(t, "stack_name: demo\nregion: us-east-1\nuse_default_bucket: true\n")
+
+	const arn = "arn:aws:cloudformation:us-east-1:123456789012:changeSet/samcli-deploy1/abc"
+	rec := &runner.Recorder{
+		Handle: func(c runner.Command) error {
+			switch {
+			case c.Name == "sam":
+				fmt.Fprintln(c.Stdout, "Changeset created successfully. "+arn)
+			case strings.Contains(c.String(), "describe-change-set"):
+				fmt.Fprintln(c.Stdout, `{"Status":"CREATE_COMPLETE","Changes":[{"Type":"Resource","ResourceChange":{"Action":"Add","LogicalResourceId":"demoFunction","ResourceType":"AWS::Lambda::Function"}}]}`)
+			case strings.Contains(c.String(), "--query"):
+				fmt.Fprintln(c.Stdout, "UPDATE_COMPLETE")
+			}
+			return nil
+		},
+	}
+	stdout, stderr, err := executeOutput(t, rec, "deploy", "--no-input", "--plan=json")
+	if err != nil {
+		t.Fatalf("deploy --plan=json error = %v", err)
+	}
+
+	var plan DeployPlan
+	if err := json.Unmarshal([]byte(stdout), &plan); err != nil {
+		t.Fatalf("stdout is not a plan: %v\n%s", err, stdout)
+	}
+	if plan.ChangeSet != arn || plan.Summary.Add != 1 {
+		t.Errorf("plan = %+v", plan)
+	}
+	for _, progress := range []string{"🚀 Deploying demo", "🗑️  Changeset discarded"} {
+		if !strings.Contains(stderr, progress) {
+			t.Errorf("stderr lacks %q:\n%s", progress, stderr)
+		}
+	}
+	for _, line := range rec.Lines() {
+		if strings.Contains(line, "delete-stack") {
+			t.Errorf("deleted the existing stack: %s", line)
+		}
+	}
+}
+
+func TestDeployPlanDiscardNewStack(t *testing.T) {
+	newProject(t, "stack_name: demo\nregion: us-east-1\nuse_default_bucket: true\n")
+
+	const arn = "arn:aws:cloudformation:us-east-1:123456789012:changeSet/samcli-deploy1/abc"
+	rec := &runner.Recorder{
+		Handle: func(c runner.Command) error {
+			switch {
+			case c.Name == "sam":
+				fmt.Fprintln(c.Stdout, "Changeset created successfully. "+arn)
+			case strings.Contains(c.String(), "describe-change-set"):
+				fmt.Fprintln(c.Stdout, `{"Status":"CREATE_COMPLETE","Changes":[]}`)
+			case strings.Contains(c.String(), "--query"):
+				// sam created the stack for its first changeset
+				fmt.Fprintln(c.Stdout, "REVIEW_IN_PROGRESS")
+			}
+			return nil
+		},
+	}
+	// Without --yes or a terminal the changeset is discarded
+	if err := execute(t, rec, "deploy", "--no-input", "--plan"); err != nil {
+		t.Fatalf("deploy --plan error = %v", err)
+	}
+
+	want := []string{
+		"sam deploy --stack-name demo --region us-east-1 --no-execute-changeset --capabilities CAPABILITY_IAM --resolve-s3",
+		"aws cloudformation describe-change-set --change-set-name " + arn + " --region us-east-1 --output json",
+		"aws cloudformation delete-change-set --change-set-name " + arn + " --region us-east-1",
+		"aws cloudformation describe-stacks --stack-name demo --region us-east-1 --query Stacks[0].StackStatus --output text",
+		"aws cloudformation delete-stack --stack-name demo --region us-east-1",
+	}
+	if got := rec.Lines(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ran\n  %s\nwant\n  %s", strings.Join(got, "\n  "), strings.Join(want, "\n  "))
+	}
+}
+
+// TestDeployStubSam runs deploy against a stub sam script on PATH.
+func TestDeployStubSam(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the stub sam is a shell script")
+	}
+
+	bin := t.TempDir()
+	argsFile := filepath.Join(bin, "args")
+	stub := "#!/bin/sh\nprintf '%s\\n' \"$@\" > \"$SAM_ARGS\"\necho \"AWS_PROFILE=$AWS_PROFILE\" >> \"$SAM_ARGS\"\n"
+	if err := os.WriteFile(filepath.Join(bin, "sam"), []byte(stub), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin)
+	t.Setenv("SAM_ARGS", argsFile)
+
+	dir := newProject(t, "stack_name: demo\nregion: us-east-1\ns3_bucket: artifacts\nenv:\n  AWS_PROFILE: ci\ntags:\n  team: core\n")
+	if err := execute(t, runner.Exec{}, "deploy", "--no-input", "--yes", "--parameter-overrides", "Stage=dev"); err != nil {
+		t.Fatalf("deploy error = %v", err)
+	}
+
+	got, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		"deploy", "--stack-name", "demo", "--region", "us-east-1", "--no-confirm-changeset",
+		"--capabilities", "CAPABILITY_IAM", "--s3-bucket", "artifacts",
+		"--parameter-overrides", "Stage=dev", "--tags", "team=core",
+		"AWS_PROFILE=ci",
+	}, "\n") + "\n"
+	if string(got) != want {
+		t.Errorf("sam was run with\n%s\nwant\n%s", got, want)
+	}
+
+	// Flags given for a single deploy are not saved
+	saved, err := os.ReadFile(filepath.Join(dir, "ginboot-app.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(saved), "Stage") {
+		t.Errorf("ginboot-app.yml was rewritten:\n%s", saved)
+	}
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/klass-lk/ginboot-cli/internal/runner"
+	"github.com/spf13/cobra"
+)
+
+var (
+	destroyEnv          string
+	destroyConfig       string
+	destroyYes          bool
+	destroyNoInput      bool
+	destroyRemoveConfig bool
+)
+
+var destroyCmd = &cobra.Command{
+	Use:   "destroy",
+	Short: "Delete the deployed stack",
+	Long: `Delete the CloudFormation stack created by ginboot deploy, along with its artifacts.
+
+The stack name and region are read from ginboot-app.yml (or --config), for the environment
+chosen with --env. The stack's resources are listed and the stack name must be typed to
+confirm, unless --yes is given. --remove-config also removes the environment (or the whole
+file, without environments) from the configuration; otherwise it is kept, and only asked
+about when prompting for the confirmation.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := cmd.OutOrStdout()
+		project, err := loadProject()
+		if err != nil {
+			return err
+		}
+		if !project.HasLambda() {
+			return fmt.Errorf("❌ %s is configured for the %s deploy target; ginboot destroy only supports lambda projects", project.ProjectName, project.Deploy)
+		}
+
+		if _, err := tools.LookPath("sam"); err != nil {
+			return fmt.Errorf("❌ SAM CLI is not installed. Please install it first: https://docs.aws.amazon.com/serverless-application-model/latest/developerguide/serverless-sam-cli-install.html")
+		}
+
+		app, config, env, environ, err := deployedStack(destroyConfig, destroyEnv)
+		if err != nil {
+			return err
+		}
+
+		interactive := !destroyNoInput && stdinIsTerminal()
+		if !interactive && !destroyYes {
+			return fmt.Errorf("❌ refusing to delete stack %s without confirmation; pass --yes", config.StackName)
+		}
+
+		fmt.Fprintf(out, "🗑️  Destroying stack %s in %s\n\n", config.StackName, config.Region)
+		if _, err := tools.LookPath("aws"); err != nil {
+			fmt.Fprintln(out, "⚠️  AWS CLI not found; cannot list the stack's resources")
+		} else if err := printStackResources(cmd.Context(), out, config, environ); err != nil {
+			return err
+		}
+		fmt.Fprintln(out)
+
+		if !destroyYes {
+			answer := promptUser(out, fmt.Sprintf("Type the stack name (%s) to confirm", config.StackName), "")
+			if answer != config.StackName {
+				return fmt.Errorf("❌ Destroy cancelled")
+			}
+		}
+
+		deleteArgs := []string{
+			"delete",
+			"--stack-name", config.StackName,
+			"--region", config.Region,
+			"--no-prompts",
+		}
+		if !config.UseDefaultBucket && config.S3Bucket != "" {
+			deleteArgs = append(deleteArgs, "--s3-bucket", config.S3Bucket)
+		}
+
+		fmt.Fprintln(out, "🔨 Deleting stack...")
+		err = tools.Run(cmd.Context(), runner.Command{
+			Name:   "sam",
+			Args:   deleteArgs,
+			Env:    environ,
+			Stdout: out,
+			Stderr: cmd.ErrOrStderr(),
+		})
+		if err != nil {
+			return fmt.Errorf("❌ Destroy failed: %w", err)
+		}
+		fmt.Fprintf(out, "\n✨ Stack %s deleted\n", config.StackName)
+
+		// --yes answers the stack confirmation only; the configuration is
+		// kept unless --remove-config is given
+		removeConfig := destroyRemoveConfig
+		if !removeConfig && interactive && !destroyYes {
+			what := fmt.Sprintf("environment %s from %s", env, destroyConfig)
+			if env == "" {
+				what = destroyConfig
+			}
+			answer := promptUser(out, fmt.Sprintf("Remove %s? (y/N)", what), "N")
+			removeConfig = strings.EqualFold(answer, "y")
+		}
+		if removeConfig {
+			return removeDeployConfig(out, app, env)
+		}
+		return nil
+	},
+}
+
+// printStackResources lists the resources of the stack.
+func printStackResources(ctx context.Context, out io.Writer, config DeployConfig, env []string) error {
+	output, err := runAWS(ctx, env, "cloudformation", "list-stack-resources", "--stack-name", config.StackName, "--region", config.Region, "--output", "json")
+	if err != nil {
+		if strings.Contains(err.Error(), "does not exist") {
+			return fmt.Errorf("❌ stack %s does not exist in %s", config.StackName, config.Region)
+		}
+		return fmt.Errorf("❌ Failed to list stack resources: %w", err)
+	}
+
+	var resources struct {
+		StackResourceSummaries []struct {
+			LogicalResourceId  string
+			PhysicalResourceId string
+			ResourceType       string
+		}
+	}
+	if err := json.Unmarshal(output, &resources); err != nil {
+		return fmt.Errorf("❌ Failed to parse stack resources: %w", err)
+	}
+
+	fmt.Fprintf(out, "The following %d resource(s) will be deleted:\n\n", len(resources.StackResourceSummaries))
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  LOGICAL ID\tTYPE\tPHYSICAL ID")
+	for _, r := range resources.StackResourceSummaries {
+		fmt.Fprintf(tw, "- %s\t%s\t%s\n", r.LogicalResourceId, r.ResourceType, r.PhysicalResourceId)
+	}
+	return tw.Flush()
+}
+
+// removeDeployConfig removes env from the config file, or the file itself
+// when the top-level settings were destroyed and it has no environments.
+func removeDeployConfig(out io.Writer, app *AppConfig, env string) error {
+	if env == "" {
+		if len(app.Environments) > 0 {
+			fmt.Fprintf(out, "⚠️  Keeping %s: it also defines environments\n", destroyConfig)
+			return nil
+		}
+		if err := os.Remove(destroyConfig); err != nil {
+			return fmt.Errorf("❌ Failed to remove %s: %w", destroyConfig, err)
+		}
+		fmt.Fprintf(out, "🗑️  Removed %s\n", destroyConfig)
+		return nil
+	}
+
+	delete(app.Environments, env)
+	if app.DefaultEnvironment == env {
+		app.DefaultEnvironment = ""
+	}
+	if err := saveConfig(destroyConfig, app); err != nil {
+		return fmt.Errorf("❌ %w", err)
+	}
+	fmt.Fprintf(out, "🗑️  Removed environment %s from %s\n", env, destroyConfig)
+	return nil
+}
+
+func init() {
+	destroyCmd.Flags().StringVarP(&destroyEnv, "env", "e", "", "Environment from ginboot-app.yml to destroy (default: default_environment)")
+	destroyCmd.Flags().StringVar(&destroyConfig, "config", "ginboot-app.yml", "Path of the deployment configuration file")
+	destroyCmd.Flags().BoolVarP(&destroyYes, "yes", "y", false, "Delete without asking for confirmation")
+	destroyCmd.Flags().BoolVar(&destroyNoInput, "no-input", false, "Never prompt; fail unless --yes is given")
+	destroyCmd.Flags().BoolVar(&destroyRemoveConfig, "remove-config", false, "Also remove the environment from the configuration file")
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/klass-lk/ginboot-cli/internal/runner"
+)
+
+func TestDestroyKeepsConfig(t *testing.T) {
+	newProject(t, "stack_name: demo\nregion: us-east-1\nuse_default_bucket: true\n")
+
+	// Answer yes to any prompt; --yes must not ask about the configuration
+	stdin, err := os.CreateTemp(t.TempDir(), "stdin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdin.Close()
+	if _, err := stdin.WriteString("y\n"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stdin.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	savedStdin, savedTerminal := os.Stdin, stdinIsTerminal
+	os.Stdin, stdinIsTerminal = stdin, func() bool { return true }
+	t.Cleanup(func() { os.Stdin, stdinIsTerminal = savedStdin, savedTerminal })
+
+	rec := &runner.Recorder{Missing: []string{"aws"}}
+	if err := execute(t, rec, "destroy", "--yes"); err != nil {
+		t.Fatalf("destroy error = %v", err)
+	}
+	if got, want := rec.Lines(), []string{"sam delete --stack-name demo --region us-east-1 --no-prompts"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ran %q, want %q", got, want)
+	}
+	if _, err := os.Stat("ginboot-app.yml"); err != nil {
+		t.Errorf("destroy --yes removed the configuration: %v", err)
+	}
+
+	if err := execute(t, rec, "destroy", "--yes", "--remove-config"); err != nil {
+		t.Fatalf("destroy --remove-config error = %v", err)
+	}
+	if _, err := os.Stat("ginboot-app.yml"); !os.IsNotExist(err) {
+		t.Errorf("destroy --remove-config kept the configuration: %v", err)
+	}
+}
+
+func TestDestroyYes(t *testing.T) {
+	newProject(t, "stack_name: demo\nregion: us-east-1\ns3_bucket: artifacts\n")
+
+	rec := &runner.Recorder{Handle: func(c runner.Command) error {
+		if c.Name == "aws" {
+			fmt.Fprint(c.Stdout, `{"StackResourceSummaries": [
+				{"LogicalResourceId": "demoFunction", "PhysicalResourceId": "demo-demoFunction-abc", "ResourceType": "AWS::Lambda::Function"}
+			]}`)
+		}
+		return nil
+	}}
+	stdout, _, err := executeOutput(t, rec, "destroy", "--yes", "--no-input")
+	if err != nil {
+		t.Fatalf("destroy error = %v", err)
+	}
+
+	want := []string{
+		"aws cloudformation list-stack-resources --stack-name demo --region us-east-1 --output json",
+		"sam delete --stack-name demo --region us-east-1 --no-prompts --s3-bucket artifacts",
+	}
+	if got := rec.Lines(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ran %q, want %q", got, want)
+	}
+	for _, line := range []string{
+		"🗑️  Destroying stack demo in us-east-1",
+		"The following 1 resource(s) will be deleted:",
+		"- demoFunction  AWS::Lambda::Function  demo-demoFunction-abc",
+		"✨ Stack demo deleted",
+	} {
+		if !strings.Contains(stdout, line+"\n") {
+			t.Errorf("output does not contain %q:\n%s", line, stdout)
+		}
+	}
+}
+
+func TestDestroyWithoutConfirmation(t *testing.T) {
+	newProject(t, "stack_name: demo\nregion: us-east-1\nuse_default_bucket: true\n")
+
+	rec := &runner.Recorder{}
+	err := execute(t, rec, "destroy", "--no-input")
+	if err == nil || !strings.Contains(err.Error(), "--yes") {
+		t.Errorf("destroy error = %v, want --yes required", err)
+	}
+	if lines := rec.Lines(); len(lines) != 0 {
+		t.Errorf("ran %q, want nothing", lines)
+	}
+}
+
+func TestDestroyMissingStack(t *testing.T) {
+	newProject(t, "stack_name: demo\nregion: us-east-1\nuse_default_bucket: true\n")
+
+	rec := &runner.Recorder{Handle: func(c runner.Command) error {
+		fmt.Fprintln(c.Stderr, "An error occurred (ValidationError) when calling the ListStackResources operation: Stack with id demo does not exist")
+		return errors.New("exit status 254")
+	}}
+	_, _, err := executeOutput(t, rec, "destroy", "--yes", "--no-input")
+	if err == nil || !strings.Contains(err.Error(), "stack demo does not exist in us-east-1") {
+		t.Errorf("destroy error = %v, want the missing stack reported", err)
+	}
+	want := []string{"aws cloudformation list-stack-resources --stack-name demo --region us-east-1 --output json"}
+	if got := rec.Lines(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ran %q, want %q without sam delete", got, want)
+	}
+	if _, err := os.Stat("ginboot-app.yml"); err != nil {
+		t.Errorf("destroy of a missing stack removed the configuration: %v", err)
+	}
+}
@@ -0,0 +1,309 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/klass-lk/ginboot-cli/internal/manifest"
+	"github.com/klass-lk/ginboot-cli/internal/runner"
+	"github.com/klass-lk/ginboot-cli/internal/watch"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	devCompose  bool
+	devDebounce time.Duration
+)
+
+// devStopTimeout is how long the app has to shut down before a restart kills
+// it.
+const devStopTimeout = 5 * time.Second
+
+var devCmd = &cobra.Command{
+	Use:   "dev",
+	Short: "Run the project and restart it when files change",
+	Long: `Build and run the project locally, and rebuild and restart it whenever a Go file,
+go.mod, go.sum or a template (*.tmpl, *.html, *.gohtml) changes.
+
+The running app is interrupted and given a few seconds to shut down before the new
+build starts. When a build fails, the compiler errors are shown and the previous build
+keeps running until the next change. --compose first starts the dependencies defined
+in docker-compose.yml (every service but app, such as the database) and stops them on
+exit.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := cmd.OutOrStdout()
+		project, err := loadProject()
+		if err != nil {
+			return err
+		}
+		if _, err := tools.LookPath("go"); err != nil {
+			return fmt.Errorf("❌ Go is not installed. Please install it first: https://go.dev/doc/install")
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		if devCompose {
+			services, err := startCompose(ctx, out)
+			if err != nil {
+				return err
+			}
+			defer stopCompose(out, services)
+		}
+
+		tmp, err := os.MkdirTemp("", "ginboot-dev-*")
+		if err != nil {
+			return fmt.Errorf("❌ %w", err)
+		}
+		defer os.RemoveAll(tmp)
+
+		changes := make(chan []string)
+		watcher := &watch.Watcher{Root: ".", Match: devWatched, Skip: devSkipped, Interval: 250 * time.Millisecond, Debounce: devDebounce}
+		watchErr := make(chan error, 1)
+		go func() { watchErr <- watcher.Watch(ctx, changes) }()
+
+		fmt.Fprintf(out, "🚀 Running %s in development mode (Ctrl+C to stop)\n", project.ProjectName)
+		session := &devSession{project: project, binary: filepath.Join(tmp, project.ProjectName), out: out, errOut: cmd.ErrOrStderr()}
+		session.rebuild(ctx)
+		defer session.stopApp()
+
+		for {
+			select {
+			case <-ctx.Done():
+				fmt.Fprintln(out, "\n👋 Stopping...")
+				return nil
+			case err := <-watchErr:
+				if err != nil {
+					return fmt.Errorf("❌ Failed to watch for changes: %w", err)
+				}
+			case paths := <-changes:
+				fmt.Fprintf(out, "\n🔄 %s\n", describeChanges(paths))
+				session.rebuild(ctx)
+			case <-session.exited():
+				if err := session.app.err; err != nil {
+					fmt.Fprintf(out, "⚠️  %s exited: %v\n", project.ProjectName, err)
+				} else {
+					fmt.Fprintf(out, "ℹ️  %s exited\n", project.ProjectName)
+				}
+				session.app = nil
+				fmt.Fprintln(out, "⏳ Waiting for changes...")
+			}
+		}
+	},
+}
+
+// devSession builds and runs the app of a `ginboot dev` session.
+type devSession struct {
+	project *manifest.Manifest
+	binary  string
+	app     *devApp
+
+	// out and errOut receive the session's messages and the app's output.
+	out, errOut io.Writer
+}
+
+// devApp is a running build of the app.
+type devApp struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error // Set once done is closed
+}
+
+// rebuild builds the app and, if that succeeds, replaces the running app
+// with the new build.
+func (s *devSession) rebuild(ctx context.Context) {
+	p := startPhase(s.out, "go build", false)
+	err := tools.Run(ctx, runner.Command{Name: "go", Args: []string{"build", "-o", s.binary, "."}, Stdout: p, Stderr: p})
+	p.end(err)
+	if err != nil {
+		if s.app != nil {
+			fmt.Fprintln(s.out, "⏳ Keeping the previous build running; waiting for changes...")
+		} else {
+			fmt.Fprintln(s.out, "⏳ Waiting for changes...")
+		}
+		return
+	}
+
+	if s.app != nil {
+		fmt.Fprintf(s.out, "🔁 Restarting %s\n", s.project.ProjectName)
+		s.stopApp()
+	}
+	s.startApp(ctx)
+}
+
+func (s *devSession) startApp(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	app := &devApp{cancel: cancel, done: make(chan struct{})}
+	go func() {
+		defer close(app.done)
+		app.err = tools.Run(ctx, runner.Command{
+			Name:        s.binary,
+			Env:         devEnv(s.project),
+			Stdout:      s.out,
+			Stderr:      s.errOut,
+			GracePeriod: devStopTimeout,
+		})
+	}()
+	s.app = app
+}
+
+// stopApp interrupts the running app and waits for it to exit.
+func (s *devSession) stopApp() {
+	if s.app == nil {
+		return
+	}
+	s.app.cancel()
+	<-s.app.done
+	s.app = nil
+}
+
+// exited is closed when the running app exits; it blocks forever when no
+// app is running.
+func (s *devSession) exited() <-chan struct{} {
+	if s.app == nil {
+		return nil
+	}
+	return s.app.done
+}
+
+// devEnv is the environment of the app: DB_NAME is set like
+// docker-compose.yml does, unless it is already set.
+func devEnv(project *manifest.Manifest) []string {
+	switch project.Database {
+	case "none", "dynamodb":
+		return nil
+	}
+	if _, ok := os.LookupEnv("DB_NAME"); ok {
+		return nil
+	}
+	return []string{"DB_NAME=" + project.ProjectName}
+}
+
+// devWatched reports whether a change to the file restarts the app.
+func devWatched(file string) bool {
+	name := path.Base(file)
+	switch {
+	case strings.HasSuffix(name, "_test.go"):
+		return false
+	case name == "go.mod", name == "go.sum":
+		return true
+	}
+	switch path.Ext(name) {
+	case ".go", ".tmpl", ".html", ".gohtml":
+		return true
+	}
+	return false
+}
+
+// devSkipped reports whether the directory is never watched.
+func devSkipped(dir string) bool {
+	name := path.Base(dir)
+	return strings.HasPrefix(name, ".") || name == "vendor" || name == "node_modules" || name == "bin" || name == "testdata"
+}
+
+// describeChanges summarizes the changed files, e.g. "main.go and 2 more
+// files changed".
+func describeChanges(paths []string) string {
+	switch len(paths) {
+	case 1:
+		return paths[0] + " changed"
+	case 2:
+		return paths[0] + " and " + paths[1] + " changed"
+	}
+	return fmt.Sprintf("%s and %d more files changed", paths[0], len(paths)-1)
+}
+
+// composeServices returns the services of a docker-compose.yml the app
+// depends on: every service but app.
+func composeServices(data []byte) ([]string, error) {
+	var compose struct {
+		Services map[string]interface{} `yaml:"services"`
+	}
+	if err := yaml.Unmarshal(data, &compose); err != nil {
+		return nil, err
+	}
+
+	var services []string
+	for name := range compose.Services {
+		if name != "app" {
+			services = append(services, name)
+		}
+	}
+	sort.Strings(services)
+	return services, nil
+}
+
+// composeCommand returns the Docker Compose command: `docker compose`, or
+// the standalone docker-compose.
+func composeCommand() (string, []string, error) {
+	if _, err := tools.LookPath("docker"); err == nil {
+		return "docker", []string{"compose"}, nil
+	}
+	if _, err := tools.LookPath("docker-compose"); err == nil {
+		return "docker-compose", nil, nil
+	}
+	return "", nil, fmt.Errorf("❌ Docker Compose is not installed. Please install it first: https://docs.docker.com/compose/install/")
+}
+
+// startCompose starts the dependencies of docker-compose.yml and returns
+// them.
+func startCompose(ctx context.Context, out io.Writer) ([]string, error) {
+	data, err := os.ReadFile("docker-compose.yml")
+	if err != nil {
+		return nil, fmt.Errorf("❌ Failed to read docker-compose.yml: %w", err)
+	}
+	services, err := composeServices(data)
+	if err != nil {
+		return nil, fmt.Errorf("❌ Failed to parse docker-compose.yml: %w", err)
+	}
+	if len(services) == 0 {
+		fmt.Fprintln(out, "ℹ️  docker-compose.yml has no dependencies to start")
+		return nil, nil
+	}
+
+	name, args, err := composeCommand()
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(out, "🐳 Starting %s\n", strings.Join(services, ", "))
+	p := startPhase(out, "docker compose up", false)
+	err = tools.Run(ctx, runner.Command{Name: name, Args: append(append(args, "up", "-d"), services...), Stdout: p, Stderr: p})
+	p.end(err)
+	if err != nil {
+		return nil, fmt.Errorf("❌ Failed to start docker-compose.yml dependencies: %w", err)
+	}
+	return services, nil
+}
+
+// stopCompose stops the services started by startCompose; their data is
+// kept for the next session.
+func stopCompose(out io.Writer, services []string) {
+	if len(services) == 0 {
+		return
+	}
+	name, args, err := composeCommand()
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(out, "🐳 Stopping %s\n", strings.Join(services, ", "))
+	// The session's context is done by now
+	err = tools.Run(context.Background(), runner.Command{Name: name, Args: append(append(args, "stop"), services...)})
+	if err != nil {
+		fmt.Fprintf(out, "⚠️  Failed to stop %s: %v\n", strings.Join(services, ", "), err)
+	}
+}
+
+func init() {
+	devCmd.Flags().BoolVar(&devCompose, "compose", false, "Start the docker-compose.yml dependencies (e.g. the database) first")
+	devCmd.Flags().DurationVar(&devDebounce, "debounce", 300*time.Millisecond, "How long files must be left alone before a rebuild")
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/klass-lk/ginboot-cli/internal/manifest"
+	"github.com/klass-lk/ginboot-cli/internal/runner"
+)
+
+func TestDevWatched(t *testing.T) {
+	for path, want := range map[string]bool{
+		"main.go":                            true,
+		"internal/controller/user.go":        true,
+		"internal/controller/user_test.go":   false,
+		"go.mod":                             true,
+		"go.sum":                             true,
+		"templates/index.html":               true,
+		"internal/mail/welcome.tmpl":         true,
+		"README.md":                          false,
+		"docker-compose.yml":                 false,
+		"internal/model/testdata/users.json": false,
+	} {
+		if got := devWatched(path); got != want {
+			t.Errorf("devWatched(%s) = %v, want %v", path, got, want)
+		}
+	}
+
+	for dir, want := range map[string]bool{
+		".git":         true,
+		".aws-sam":     true,
+		"vendor":       true,
+		"bin":          true,
+		"internal":     false,
+		"internal/web": false,
+	} {
+		if got := devSkipped(dir); got != want {
+			t.Errorf("devSkipped(%s) = %v, want %v", dir, got, want)
+		}
+	}
+}
+
+func TestComposeServices(t *testing.T) {
+	compose := `version: '3.8'
+services:
+  app:
+    build: .
+  postgres:
+    image: postgres:15
+  minio:
+    image: minio/minio
+`
+	services, err := composeServices([]byte(compose))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"minio", "postgres"}; !reflect.DeepEqual(services, want) {
+		t.Errorf("composeServices() = %q, want %q", services, want)
+	}
+}
+
+func TestDevRebuild(t *testing.T) {
+	compileErr := true
+	rec := &runner.Recorder{
+		Handle: func(c runner.Command) error {
+			if c.Name == "go" && compileErr {
+				fmt.Fprintln(c.Stderr, "./main.go:3:1: syntax error")
+				return errors.New("exit status 1")
+			}
+			return nil
+		},
+	}
+	saved := tools
+	tools = rec
+	t.Cleanup(func() { tools = saved })
+
+	project := &manifest.Manifest{ProjectName: "demo", Database: "postgres"}
+	session := &devSession{project: project, binary: "/tmp/demo", out: io.Discard, errOut: io.Discard}
+
+	// A failed build starts nothing
+	session.rebuild(context.Background())
+	if session.app != nil {
+		t.Fatal("app started after a failed build")
+	}
+
+	compileErr = false
+	session.rebuild(context.Background())
+	<-session.exited()
+	session.stopApp()
+
+	want := []string{"go build -o /tmp/demo .", "go build -o /tmp/demo .", "/tmp/demo"}
+	if got := rec.Lines(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ran %q, want %q", got, want)
+	}
+	if env := rec.Commands()[2].Env; !reflect.DeepEqual(env, []string{"DB_NAME=demo"}) {
+		t.Errorf("app env = %q, want DB_NAME=demo", env)
+	}
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/klass-lk/ginboot-cli/internal/generator"
+	"github.com/spf13/cobra"
+)
+
+var (
+	resourceFields  string
+	generateOffline bool
+)
+
+var generateCmd = &cobra.Command{
+	Use:     "generate",
+	Aliases: []string{"g"},
+	Short:   "Generate code inside an existing Ginboot project",
+}
+
+var generateResourceCmd = &cobra.Command{
+	Use:   "resource [name]",
+	Short: "Generate a CRUD model, repository, service and controller",
+	Long: `Generate a model, repository, service and controller with List/Get/Create/Update/Delete
+routes for a new entity and register them in internal/di/container.go.
+
+Example:
+  ginboot generate resource Order --fields "total:float64,status:string"`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := cmd.OutOrStdout()
+		resource, err := generator.NewResource(args[0], resourceFields)
+		if err != nil {
+			return err
+		}
+
+		project, err := loadProject()
+		if err != nil {
+			return err
+		}
+
+		gen, err := projectGenerator(cmd.Context(), project, generateOffline)
+		if err != nil {
+			return err
+		}
+		if err := gen.GenerateResource(project, resource); err != nil {
+			return fmt.Errorf("failed to generate resource: %w", err)
+		}
+
+		fmt.Fprintf(out, "✨ Generated %s resource (Database: %s)\n", resource.Name, project.Database)
+		fmt.Fprintf(out, "🔗 Routes registered under /api/v1/%s\n", resource.Route)
+		return nil
+	},
+}
+
+func init() {
+	generateResourceCmd.Flags().StringVar(&resourceFields, "fields", "", "Comma-separated model fields as name:type (e.g. \"total:float64,status:string\")")
+	generateCmd.PersistentFlags().BoolVar(&generateOffline, "offline", false, "Never access the network; use the cached checkout of the project's template pack")
+	generateCmd.AddCommand(generateResourceCmd)
+}
@@ -0,0 +1,262 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klass-lk/ginboot-cli/internal/lambdalocal"
+	"github.com/klass-lk/ginboot-cli/internal/manifest"
+	"github.com/klass-lk/ginboot-cli/internal/runner"
+	"github.com/spf13/cobra"
+)
+
+var (
+	invokeEvent   string
+	invokeTimeout time.Duration
+	invokeStage   string
+)
+
+var invokeCmd = &cobra.Command{
+	Use:   "invoke",
+	Short: "Invoke the Lambda function locally with an event",
+	Long: `Build the project and invoke its Lambda handler once with an event, without SAM CLI or
+Docker. The handler runs as a local process against an emulated Lambda Runtime API, so
+LAMBDA_TASK_ROOT is set and the code path taken on AWS Lambda is the one exercised.
+
+The event is read from the file given with --event ("-" for stdin; {} by default). The
+function's response is printed on stdout; build progress and the function's logs go to
+stderr.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		project, err := loadProject()
+		if err != nil {
+			return err
+		}
+		if !project.HasLambda() {
+			return fmt.Errorf("❌ %s is configured for the %s deploy target; ginboot invoke only supports lambda projects", project.ProjectName, project.Deploy)
+		}
+
+		event := []byte("{}")
+		switch invokeEvent {
+		case "":
+		case "-":
+			event, err = io.ReadAll(os.Stdin)
+		default:
+			event, err = os.ReadFile(invokeEvent)
+		}
+		if err != nil {
+			return fmt.Errorf("❌ Failed to read the event: %w", err)
+		}
+
+		// The response is the only output on stdout
+		fn, err := startLocalFunction(cmd.Context(), project, invokeStage, cmd.ErrOrStderr(), cmd.ErrOrStderr())
+		if err != nil {
+			return err
+		}
+		defer fn.close()
+
+		resp, err := fn.invoke(cmd.Context(), event, invokeTimeout)
+		if err != nil {
+			return fmt.Errorf("❌ Invocation failed: %w", err)
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "📊 REPORT RequestId: %s Duration: %s\n", resp.RequestID, formatDuration(resp.Duration))
+		fmt.Fprintln(cmd.OutOrStdout(), strings.TrimSpace(string(resp.Payload)))
+		if resp.Error != nil {
+			return fmt.Errorf("❌ Function error: %w", resp.Error)
+		}
+		return nil
+	},
+}
+
+// localFunction runs the project's Lambda handler as a local process against
+// a Runtime API emulator. Like an execution environment, it handles one
+// invocation at a time, and the process is started for the first invocation
+// and again after it exited or timed out.
+type localFunction struct {
+	ctx    context.Context // Of the session the process runs for
+	name   string
+	binary string
+	env    []string
+	logs   io.Writer
+	emu    *lambdalocal.Emulator
+	turn   chan struct{} // Holds a value while an invocation is in progress
+
+	mu      sync.Mutex
+	cancel  context.CancelFunc
+	exited  chan struct{} // Closed when the process exits
+	exitErr error
+}
+
+// startLocalFunction builds the handler for this machine, reporting to out,
+// and starts the Runtime API emulator; the function's output goes to logs.
+func startLocalFunction(ctx context.Context, project *manifest.Manifest, stage string, out, logs io.Writer) (*localFunction, error) {
+	if _, err := tools.LookPath("go"); err != nil {
+		return nil, fmt.Errorf("❌ Go is not installed. Please install it first: https://go.dev/doc/install")
+	}
+
+	tmp, err := os.MkdirTemp("", "ginboot-lambda-*")
+	if err != nil {
+		return nil, fmt.Errorf("❌ %w", err)
+	}
+	binary := filepath.Join(tmp, "bootstrap")
+	p := startPhase(out, "go build", false)
+	err = tools.Run(ctx, runner.Command{Name: "go", Args: []string{"build", "-o", binary, "."}, Stdout: p, Stderr: p})
+	p.end(err)
+	if err != nil {
+		os.RemoveAll(tmp)
+		return nil, fmt.Errorf("❌ Build failed: %w", err)
+	}
+
+	name := project.ProjectName + "Function"
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = "us-east-1"
+	}
+	emu := lambdalocal.New(fmt.Sprintf("arn:aws:lambda:%s:123456789012:function:%s", region, name))
+	addr, err := emu.Start("127.0.0.1:0")
+	if err != nil {
+		os.RemoveAll(tmp)
+		return nil, fmt.Errorf("❌ Failed to start the Lambda Runtime API emulator: %w", err)
+	}
+
+	taskRoot, err := filepath.Abs(".")
+	if err != nil {
+		taskRoot = "."
+	}
+	env := []string{
+		"AWS_LAMBDA_RUNTIME_API=" + addr,
+		"LAMBDA_TASK_ROOT=" + taskRoot,
+		"_HANDLER=bootstrap",
+		"AWS_LAMBDA_FUNCTION_NAME=" + name,
+		"AWS_LAMBDA_FUNCTION_VERSION=$LATEST",
+		"AWS_LAMBDA_FUNCTION_MEMORY_SIZE=128",
+		"AWS_REGION=" + region,
+	}
+	// The defaults of the template.yaml parameters, unless already set
+	defaults := append([]string{"STAGE=" + stage, "LOG_LEVEL=info"}, devEnv(project)...)
+	for _, kv := range defaults {
+		name, _, _ := strings.Cut(kv, "=")
+		if _, ok := os.LookupEnv(name); !ok {
+			env = append(env, kv)
+		}
+	}
+
+	return &localFunction{ctx: ctx, name: name, binary: binary, env: env, logs: logs, emu: emu, turn: make(chan struct{}, 1)}, nil
+}
+
+// running starts the process unless it is running, and returns the channel
+// closed when it exits.
+func (f *localFunction) running() <-chan struct{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.exited != nil {
+		select {
+		case <-f.exited:
+		default:
+			return f.exited
+		}
+	}
+
+	ctx, cancel := context.WithCancel(f.ctx)
+	exited := make(chan struct{})
+	f.cancel, f.exited = cancel, exited
+	go func() {
+		err := tools.Run(ctx, runner.Command{
+			Name:        f.binary,
+			Env:         f.env,
+			Stdout:      f.logs,
+			Stderr:      f.logs,
+			GracePeriod: time.Second,
+		})
+		f.mu.Lock()
+		f.exitErr = err
+		f.mu.Unlock()
+		close(exited)
+	}()
+	return exited
+}
+
+// errTimeout is the cause of an invocation running out of time.
+var errTimeout = errors.New("function timed out")
+
+// invoke invokes the function with event and waits up to timeout for its
+// response. Invocations wait for the one in progress, including stopping
+// a process it gave up on, before their timeout starts.
+func (f *localFunction) invoke(ctx context.Context, event []byte, timeout time.Duration) (*lambdalocal.Response, error) {
+	select {
+	case f.turn <- struct{}{}:
+		defer func() { <-f.turn }()
+	case <-ctx.Done():
+		return nil, fmt.Errorf("function is busy: %w", context.Cause(ctx))
+	}
+
+	exited := f.running()
+
+	ctx, cancelTimeout := context.WithTimeoutCause(ctx, timeout, fmt.Errorf("%w after %s", errTimeout, timeout))
+	defer cancelTimeout()
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	go func() {
+		select {
+		case <-exited:
+			cancel(f.exitError())
+		case <-ctx.Done():
+		}
+	}()
+
+	resp, err := f.emu.Invoke(ctx, event)
+	if err != nil && ctx.Err() != nil {
+		// The invocation timed out or the caller gave up on it, e.g. a
+		// client of local-api disconnected. Like a timed out environment,
+		// the process may still be working on it and is not reused.
+		f.stop()
+	}
+	return resp, err
+}
+
+// exitError describes why the process exited.
+func (f *localFunction) exitError() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	msg := "function exited"
+	if f.exitErr != nil {
+		msg += ": " + f.exitErr.Error()
+	}
+	if initErr := f.emu.InitError(); initErr != nil {
+		msg += " (init error: " + initErr.Error() + ")"
+	}
+	return errors.New(msg)
+}
+
+// stop interrupts the process, if it is running, and waits for it to exit.
+func (f *localFunction) stop() {
+	f.mu.Lock()
+	cancel, exited := f.cancel, f.exited
+	f.mu.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-exited
+}
+
+// close stops the process and the emulator.
+func (f *localFunction) close() {
+	f.stop()
+	f.emu.Close()
+	os.RemoveAll(filepath.Dir(f.binary))
+}
+
+func init() {
+	invokeCmd.Flags().StringVarP(&invokeEvent, "event", "e", "", `JSON event file, or "-" to read it from stdin (default: {})`)
+	invokeCmd.Flags().DurationVar(&invokeTimeout, "timeout", 10*time.Second, "Time the function has to respond, like the Timeout of template.yaml")
+	invokeCmd.Flags().StringVar(&invokeStage, "stage", "prod", "Value of the STAGE environment variable")
+}
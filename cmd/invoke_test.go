@@ -0,0 +1,299 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/klass-lk/ginboot-cli/internal/lambdalocal"
+	"github.com/klass-lk/ginboot-cli/internal/runner"
+)
+
+// fakeFunction plays a Lambda function process: it handles n invocations
+// against the Runtime API in its environment with handle, then exits.
+func fakeFunction(t *testing.T, n int, handle func(event []byte) (action string, body []byte)) *runner.Recorder {
+	return &runner.Recorder{
+		Handle: func(c runner.Command) error {
+			if c.Name == "go" {
+				return nil
+			}
+			var api string
+			for _, kv := range c.Env {
+				if value, ok := strings.CutPrefix(kv, "AWS_LAMBDA_RUNTIME_API="); ok {
+					api = "http://" + value + "/2018-06-01/runtime/invocation/"
+				}
+			}
+			if api == "" {
+				t.Errorf("AWS_LAMBDA_RUNTIME_API is not set in %v", c.Env)
+				return nil
+			}
+
+			for range n {
+				resp, err := http.Get(api + "next")
+				if err != nil {
+					return err
+				}
+				event, _ := io.ReadAll(resp.Body)
+				resp.Body.Close()
+				action, body := handle(event)
+				resp, err = http.Post(api+resp.Header.Get("Lambda-Runtime-Aws-Request-Id")+"/"+action, "application/json", bytes.NewReader(body))
+				if err != nil {
+					return err
+				}
+				resp.Body.Close()
+			}
+			return nil
+		},
+	}
+}
+
+func TestInvoke(t *testing.T) {
+	dir := newProject(t, "")
+	if err := os.WriteFile(filepath.Join(dir, "event.json"), []byte(`{"name":"Ada"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	rec := fakeFunction(t, 1, func(event []byte) (string, []byte) {
+		return "response", append([]byte(`{"hello":`), append(event, '}')...)
+	})
+	stdout, stderr, err := executeOutput(t, rec, "invoke", "--event", "event.json", "--stage", "dev")
+	if err != nil {
+		t.Fatalf("invoke failed: %v", err)
+	}
+	if stdout != `{"hello":{"name":"Ada"}}`+"\n" {
+		t.Errorf("stdout = %q, want the response only", stdout)
+	}
+	if !strings.Contains(stderr, "📊 REPORT RequestId:") {
+		t.Errorf("stderr = %q, want the invocation report", stderr)
+	}
+
+	commands := rec.Commands()
+	if len(commands) != 2 || !strings.HasPrefix(commands[0].String(), "go build -o ") {
+		t.Fatalf("commands = %v, want go build and the function", rec.Lines())
+	}
+	for _, kv := range []string{"LAMBDA_TASK_ROOT=" + dir, "AWS_LAMBDA_FUNCTION_NAME=demoFunction", "STAGE=dev"} {
+		if !slices.Contains(commands[1].Env, kv) {
+			t.Errorf("function environment %v lacks %s", commands[1].Env, kv)
+		}
+	}
+}
+
+func TestInvokeFunctionError(t *testing.T) {
+	newProject(t, "")
+
+	rec := fakeFunction(t, 1, func([]byte) (string, []byte) {
+		return "error", []byte(`{"errorType":"errorString","errorMessage":"user not found"}`)
+	})
+	_, _, err := executeOutput(t, rec, "invoke")
+	if err == nil || !strings.Contains(err.Error(), "user not found") {
+		t.Errorf("invoke error = %v, want the function's error", err)
+	}
+}
+
+func TestProxyHandler(t *testing.T) {
+	newProject(t, "")
+	rec := fakeFunction(t, 2, func(event []byte) (string, []byte) {
+		var req lambdalocal.ProxyRequest
+		if err := json.Unmarshal(event, &req); err != nil {
+			t.Errorf("event is not a proxy request: %v", err)
+		}
+		if req.Path != "/users/1" || req.RequestContext.Stage != "prod" {
+			t.Errorf("event path = %q, stage = %q", req.Path, req.RequestContext.Stage)
+		}
+		resp, _ := json.Marshal(lambdalocal.ProxyResponse{StatusCode: http.StatusOK, Body: `{"id":"1"}`})
+		return "response", resp
+	})
+	saved := tools
+	tools = rec
+	t.Cleanup(func() { tools = saved })
+
+	m, err := loadProject()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fn, err := startLocalFunction(context.Background(), m, "prod", io.Discard, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fn.close()
+	handler := proxyHandler(fn, "prod", 5*time.Second, io.Discard)
+
+	for range 2 {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+		if w.Code != http.StatusOK || w.Body.String() != `{"id":"1"}` {
+			t.Errorf("response = %d %q", w.Code, w.Body.String())
+		}
+	}
+}
+
+// hangingFunction returns a Recorder playing a function whose first process
+// gets the first event and keeps working on it until it is interrupted,
+// closing got once it has the event. Later processes answer every event;
+// starts counts the processes started.
+func hangingFunction() (rec *runner.Recorder, got chan struct{}, starts *atomic.Int32) {
+	got = make(chan struct{})
+	starts = &atomic.Int32{}
+	rec = &runner.Recorder{
+		HandleContext: func(ctx context.Context, c runner.Command) error {
+			if c.Name == "go" {
+				return nil
+			}
+			first := starts.Add(1) == 1
+			var api string
+			for _, kv := range c.Env {
+				if value, ok := strings.CutPrefix(kv, "AWS_LAMBDA_RUNTIME_API="); ok {
+					api = "http://" + value + "/2018-06-01/runtime/invocation/"
+				}
+			}
+			for {
+				req, _ := http.NewRequestWithContext(ctx, http.MethodGet, api+"next", nil)
+				resp, err := http.DefaultClient.Do(req)
+				if err != nil {
+					return ctx.Err()
+				}
+				resp.Body.Close()
+				if first {
+					close(got)
+					<-ctx.Done()
+					return ctx.Err()
+				}
+				body, _ := json.Marshal(lambdalocal.ProxyResponse{StatusCode: http.StatusOK, Body: "ok"})
+				resp, err = http.Post(api+resp.Header.Get("Lambda-Runtime-Aws-Request-Id")+"/response", "application/json", bytes.NewReader(body))
+				if err != nil {
+					return err
+				}
+				resp.Body.Close()
+			}
+		},
+	}
+	return rec, got, starts
+}
+
+// startHangingFunction serves a hangingFunction with proxyHandler.
+func startHangingFunction(t *testing.T) (handler http.Handler, got chan struct{}, starts *atomic.Int32) {
+	t.Helper()
+	newProject(t, "")
+	rec, got, starts := hangingFunction()
+	saved := tools
+	tools = rec
+	t.Cleanup(func() { tools = saved })
+
+	m, err := loadProject()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fn, err := startLocalFunction(context.Background(), m, "prod", io.Discard, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(fn.close)
+	return proxyHandler(fn, "prod", 5*time.Second, io.Discard), got, starts
+}
+
+func TestProxyHandlerClientDisconnect(t *testing.T) {
+	handler, got, starts := startHangingFunction(t)
+
+	ctx, disconnect := context.WithCancel(context.Background())
+	served := make(chan struct{})
+	go func() {
+		defer close(served)
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users", nil).WithContext(ctx))
+	}()
+	<-got
+	disconnect()
+	<-served
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users", nil))
+	if w.Code != http.StatusOK || starts.Load() != 2 {
+		t.Errorf("response after a disconnect = %d %q from process %d, want 200 from a restarted process", w.Code, w.Body.String(), starts.Load())
+	}
+}
+
+func TestProxyHandlerDisconnectWithQueuedRequest(t *testing.T) {
+	handler, got, starts := startHangingFunction(t)
+
+	ctx, disconnect := context.WithCancel(context.Background())
+	first := make(chan struct{})
+	go func() {
+		defer close(first)
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users", nil).WithContext(ctx))
+	}()
+	<-got
+
+	// The second request waits for the first, which is then given up on
+	w := httptest.NewRecorder()
+	second := make(chan struct{})
+	go func() {
+		defer close(second)
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users", nil))
+	}()
+	time.Sleep(50 * time.Millisecond)
+	disconnect()
+	<-first
+	<-second
+
+	if w.Code != http.StatusOK || starts.Load() != 2 {
+		t.Errorf("queued response = %d %q from process %d, want 200 from a restarted process", w.Code, w.Body.String(), starts.Load())
+	}
+}
+
+// brokenConnection is a response writer whose body writes fail, like a
+// connection the client closed.
+type brokenConnection struct {
+	header       http.Header
+	writeHeaders []int
+}
+
+func (w *brokenConnection) Header() http.Header {
+	if w.header == nil {
+		w.header = http.Header{}
+	}
+	return w.header
+}
+
+func (w *brokenConnection) WriteHeader(status int) {
+	w.writeHeaders = append(w.writeHeaders, status)
+}
+
+func (w *brokenConnection) Write([]byte) (int, error) {
+	return 0, errors.New("broken pipe")
+}
+
+func TestProxyHandlerWriteFailure(t *testing.T) {
+	newProject(t, "")
+	rec := fakeFunction(t, 1, func([]byte) (string, []byte) {
+		resp, _ := json.Marshal(lambdalocal.ProxyResponse{StatusCode: http.StatusCreated, Body: `{"id":"1"}`})
+		return "response", resp
+	})
+	saved := tools
+	tools = rec
+	t.Cleanup(func() { tools = saved })
+
+	m, err := loadProject()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fn, err := startLocalFunction(context.Background(), m, "prod", io.Discard, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fn.close()
+
+	w := &brokenConnection{}
+	proxyHandler(fn, "prod", 5*time.Second, io.Discard).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/users", nil))
+	if !slices.Equal(w.writeHeaders, []int{http.StatusCreated}) {
+		t.Errorf("headers written with %v, want the function's status only", w.writeHeaders)
+	}
+}
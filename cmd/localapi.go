@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/klass-lk/ginboot-cli/internal/lambdalocal"
+	"github.com/spf13/cobra"
+)
+
+var (
+	localAPIHost    string
+	localAPIPort    int
+	localAPIStage   string
+	localAPITimeout time.Duration
+)
+
+var localAPICmd = &cobra.Command{
+	Use:   "local-api",
+	Short: "Serve the Lambda function locally behind an emulated API Gateway",
+	Long: `Build the project and serve it on a local port the way API Gateway serves it on AWS,
+without SAM CLI or Docker. Every HTTP request is translated into an API Gateway proxy
+event for the /{proxy+} resource, the Lambda handler is invoked with it against an
+emulated Lambda Runtime API, and its proxy response is translated back.
+
+Like a Lambda execution environment, the handler handles one request at a time and is
+restarted when it exits or times out. Rebuild by restarting ginboot local-api.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := cmd.OutOrStdout()
+		project, err := loadProject()
+		if err != nil {
+			return err
+		}
+		if !project.HasLambda() {
+			return fmt.Errorf("❌ %s is configured for the %s deploy target; ginboot local-api only supports lambda projects", project.ProjectName, project.Deploy)
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		fn, err := startLocalFunction(ctx, project, localAPIStage, out, cmd.ErrOrStderr())
+		if err != nil {
+			return err
+		}
+		defer fn.close()
+
+		addr := net.JoinHostPort(localAPIHost, strconv.Itoa(localAPIPort))
+		listener, err := net.Listen("tcp", addr)
+		if err != nil {
+			return fmt.Errorf("❌ Failed to listen on %s: %w", addr, err)
+		}
+		server := &http.Server{Handler: proxyHandler(fn, localAPIStage, localAPITimeout, out)}
+		go func() {
+			<-ctx.Done()
+			server.Close()
+		}()
+
+		fmt.Fprintf(out, "🌐 Serving %s on http://%s (stage %s, Ctrl+C to stop)\n", fn.name, listener.Addr(), localAPIStage)
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("❌ %w", err)
+		}
+		fmt.Fprintln(out, "\n👋 Stopping...")
+		return nil
+	},
+}
+
+// proxyHandler serves HTTP requests with the function, translating them
+// like an API Gateway proxy integration. Requests are logged to out.
+func proxyHandler(fn *localFunction, stage string, timeout time.Duration, out io.Writer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		// The status is read back from the response written
+		rw := &statusWriter{ResponseWriter: w}
+		defer func() {
+			fmt.Fprintf(out, "%s %s → %d (%s)\n", r.Method, r.URL.RequestURI(), rw.status, formatDuration(time.Since(start)))
+		}()
+
+		event, err := lambdalocal.NewProxyRequest(r, stage)
+		if err != nil {
+			gatewayError(out, rw, http.StatusBadRequest, err)
+			return
+		}
+		payload, err := json.Marshal(event)
+		if err != nil {
+			gatewayError(out, rw, http.StatusBadGateway, err)
+			return
+		}
+
+		resp, err := fn.invoke(r.Context(), payload, timeout)
+		switch {
+		case errors.Is(err, errTimeout) || errors.Is(err, context.DeadlineExceeded):
+			gatewayError(out, rw, http.StatusGatewayTimeout, err)
+			return
+		case err != nil:
+			gatewayError(out, rw, http.StatusBadGateway, err)
+			return
+		case resp.Error != nil:
+			gatewayError(out, rw, http.StatusBadGateway, resp.Error)
+			return
+		}
+
+		if err := lambdalocal.WriteProxyResponse(rw, resp.Payload); err != nil {
+			gatewayError(out, rw, http.StatusBadGateway, err)
+		}
+	})
+}
+
+// gatewayError answers like API Gateway when the integration fails, and
+// reports the cause to out. Once the function's response has been started
+// it can no longer be replaced, so only the cause is reported.
+func gatewayError(out io.Writer, w *statusWriter, status int, err error) {
+	fmt.Fprintf(out, "❌ %v\n", err)
+	if w.status != 0 {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	message := "Internal server error"
+	if status == http.StatusGatewayTimeout {
+		message = "Endpoint request timed out"
+	}
+	json.NewEncoder(w).Encode(map[string]string{"message": message})
+}
+
+// statusWriter records the status code written; it is 0 until the headers
+// are sent.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func init() {
+	localAPICmd.Flags().StringVar(&localAPIHost, "host", "127.0.0.1", "Address to listen on")
+	localAPICmd.Flags().IntVarP(&localAPIPort, "port", "p", 3000, "Port to listen on")
+	localAPICmd.Flags().StringVar(&localAPIStage, "stage", "prod", "API Gateway stage of the events, also the STAGE environment variable")
+	localAPICmd.Flags().DurationVar(&localAPITimeout, "timeout", 10*time.Second, "Time the function has to respond, like the Timeout of template.yaml")
+}
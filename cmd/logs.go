@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/klass-lk/ginboot-cli/internal/runner"
+	"github.com/spf13/cobra"
+)
+
+var (
+	logsEnv    string
+	logsConfig string
+	logsTail   bool
+	logsFilter string
+	logsSince  string
+)
+
+var logsCmd = &cobra.Command{
+	Use:   "logs",
+	Short: "Show the logs of the deployed function",
+	Long: `Show the CloudWatch logs of the project's Lambda function using SAM CLI.
+
+The stack is read from ginboot-app.yml (or --config), for the environment chosen with --env.
+--tail keeps following new log events and --filter only shows events matching a
+CloudWatch Logs filter pattern.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := cmd.OutOrStdout()
+		project, err := loadProject()
+		if err != nil {
+			return err
+		}
+		if !project.HasLambda() {
+			return fmt.Errorf("❌ %s is configured for the %s deploy target; ginboot logs only supports lambda projects", project.ProjectName, project.Deploy)
+		}
+		if _, err := tools.LookPath("sam"); err != nil {
+			return fmt.Errorf("❌ SAM CLI is not installed. Please install it first: https://docs.aws.amazon.com/serverless-application-model/latest/developerguide/serverless-sam-cli-install.html")
+		}
+
+		_, config, _, environ, err := deployedStack(logsConfig, logsEnv)
+		if err != nil {
+			return err
+		}
+
+		logsArgs := []string{
+			"logs",
+			"--name", project.ProjectName + "Function",
+			"--stack-name", config.StackName,
+			"--region", config.Region,
+		}
+		if logsTail {
+			logsArgs = append(logsArgs, "--tail")
+		}
+		if logsFilter != "" {
+			logsArgs = append(logsArgs, "--filter", logsFilter)
+		}
+		if logsSince != "" {
+			logsArgs = append(logsArgs, "--start-time", logsSince)
+		}
+
+		fmt.Fprintf(out, "📜 Logs of %sFunction in %s (%s)\n\n", project.ProjectName, config.StackName, config.Region)
+		err = tools.Run(cmd.Context(), runner.Command{
+			Name:   "sam",
+			Args:   logsArgs,
+			Env:    environ,
+			Stdout: out,
+			Stderr: cmd.ErrOrStderr(),
+		})
+		if err != nil {
+			return fmt.Errorf("❌ Failed to fetch logs: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	logsCmd.Flags().StringVarP(&logsEnv, "env", "e", "", "Environment from ginboot-app.yml (default: default_environment)")
+	logsCmd.Flags().StringVar(&logsConfig, "config", "ginboot-app.yml", "Path of the deployment configuration file")
+	logsCmd.Flags().BoolVarP(&logsTail, "tail", "t", false, "Keep following new log events")
+	logsCmd.Flags().StringVar(&logsFilter, "filter", "", "CloudWatch Logs filter pattern, e.g. ERROR")
+	logsCmd.Flags().StringVar(&logsSince, "since", "", "Show events from this time on, e.g. '5mins ago' (default: 10 minutes ago)")
+}
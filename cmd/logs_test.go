@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/klass-lk/ginboot-cli/internal/runner"
+)
+
+func TestLogs(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "defaults",
+			want: "sam logs --name demoFunction --stack-name demo --region us-east-1",
+		},
+		{
+			name: "tail and filter",
+			args: []string{"--tail", "--filter", "ERROR", "--since", "5mins ago"},
+			want: "sam logs --name demoFunction --stack-name demo --region us-east-1 --tail --filter ERROR --start-time 5mins ago",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newProject(t, "stack_name: demo\nregion: us-east-1\n")
+
+			rec := &runner.Recorder{Handle: func(c runner.Command) error {
+				fmt.Fprintln(c.Stdout, "2024/01/01/[$LATEST]abc 2024-01-01T00:00:00 GET /users 200")
+				return nil
+			}}
+			stdout, _, err := executeOutput(t, rec, append([]string{"logs"}, tt.args...)...)
+			if err != nil {
+				t.Fatalf("logs error = %v", err)
+			}
+
+			commands := rec.Commands()
+			if len(commands) != 1 {
+				t.Fatalf("ran %q, want a single sam logs", rec.Lines())
+			}
+			if got := commands[0].String(); got != tt.want {
+				t.Errorf("ran\n  %s\nwant\n  %s", got, tt.want)
+			}
+			want := "📜 Logs of demoFunction in demo (us-east-1)\n\n2024/01/01/[$LATEST]abc 2024-01-01T00:00:00 GET /users 200\n"
+			if stdout != want {
+				t.Errorf("output =\n%s\nwant\n%s", stdout, want)
+			}
+		})
+	}
+}
+
+func TestLogsWithoutConfig(t *testing.T) {
+	newProject(t, "")
+
+	rec := &runner.Recorder{}
+	err := execute(t, rec, "logs")
+	if err == nil || !strings.Contains(err.Error(), "nothing has been deployed") {
+		t.Errorf("logs error = %v, want a missing configuration error", err)
+	}
+	if lines := rec.Lines(); len(lines) != 0 {
+		t.Errorf("ran %q, want nothing", lines)
+	}
+}
@@ -2,11 +2,13 @@ package cmd
 
 import (
 	"fmt"
+	"github.com/klass-lk/ginboot-cli/internal/config"
 	"github.com/klass-lk/ginboot-cli/internal/generator"
+	"github.com/klass-lk/ginboot-cli/pkg/ginboot/scaffold"
 	"github.com/spf13/cobra"
 	"os"
 	"path/filepath"
-	"regexp"
+	"strings"
 )
 
 var (
@@ -17,6 +19,18 @@ var (
 	storageType string
 	deployType  string
 	telemetry   bool
+	newArch     string
+	newRuntime  string
+
+	ginbootVersion string
+	offline        bool
+
+	templatePack   string
+	templateValues map[string]string
+
+	dryRun   bool
+	newForce bool
+	outPath  string
 )
 
 var newCmd = &cobra.Command{
@@ -25,11 +39,15 @@ var newCmd = &cobra.Command{
 	Long:  `Create a new Ginboot project with a standard directory structure and configuration files.`,
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		out := cmd.OutOrStdout()
 		projectName = args[0]
 
-		// Validate project name (alphanumeric only)
-		if !isValidProjectName(projectName) {
-			return fmt.Errorf("invalid project name '%s': must contain only letters and numbers", projectName)
+		if err := scaffold.ValidateProjectName(projectName); err != nil {
+			return err
+		}
+
+		if outPath != "" && !isArchive(outPath) {
+			return fmt.Errorf("invalid output '%s': must end in .zip, .tar.gz or .tgz", outPath)
 		}
 
 		if moduleName == "" {
@@ -41,7 +59,7 @@ var newCmd = &cobra.Command{
 		}
 
 		if goVersion == "" {
-			goVersion = "1.21"
+			goVersion = scaffold.DefaultGoVersion
 		}
 
 		// If any required config is empty, run the Bubble Tea TUI Wizard
@@ -51,59 +69,166 @@ var newCmd = &cobra.Command{
 			if err != nil {
 				return err
 			}
-		} else {
-			// Validate flags
-			switch dbType {
-			case "none", "mongodb", "postgres", "mysql", "dynamodb":
-				// valid
-			default:
-				return fmt.Errorf("invalid database type '%s': must be one of none, mongodb, postgres, mysql, dynamodb", dbType)
-			}
+		}
+		opts := scaffold.Options{ProjectName: projectName, Database: dbType, Storage: storageType, Deploy: deployType, Arch: newArch, Runtime: newRuntime}
+		if err := opts.Validate(); err != nil {
+			return err
+		}
 
-			switch storageType {
-			case "none", "s3":
-				// valid
-			default:
-				return fmt.Errorf("invalid storage type '%s': must be one of none, s3", storageType)
-			}
+		userConfig, err := config.Load()
+		if err != nil {
+			return err
+		}
+		source, err := userConfig.Source()
+		if err != nil {
+			return err
+		}
+
+		resolved := generator.ResolveGinbootVersion(cmd.Context(), generator.VersionOptions{
+			Pinned:  ginbootVersion,
+			Offline: offline,
+			Source:  source,
+		})
+		fmt.Fprintf(out, "📦 Using Ginboot %s (%s)\n", resolved.Version, resolved.Reason)
 
-			switch deployType {
-			case "http", "lambda":
-				// valid
-			default:
-				return fmt.Errorf("invalid deployment type '%s': must be one of http, lambda", deployType)
+		var pack *generator.TemplatePack
+		if templatePack != "" {
+			pack, err = generator.LoadTemplatePack(cmd.Context(), templatePack, offline)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "🧩 Using template pack %s\n", packName(pack))
+			if templateValues == nil {
+				templateValues = map[string]string{}
+			}
+			for _, prompt := range pack.Prompts {
+				if _, ok := templateValues[prompt.Name]; ok {
+					continue
+				}
+				question := prompt.Message
+				if question == "" {
+					question = prompt.Name
+				}
+				templateValues[prompt.Name] = promptUser(out, question, prompt.Default)
 			}
 		}
 
 		projectPath := filepath.Join(".", projectName)
-		if err := os.MkdirAll(projectPath, 0755); err != nil {
-			return fmt.Errorf("failed to create project directory: %w", err)
+		gen := generator.NewProjectGenerator(projectPath, projectName, moduleName, goVersion, dbType, storageType, deployType, telemetry)
+		gen.GinbootVersion = resolved.Version
+		gen.Arch = newArch
+		gen.Runtime = newRuntime
+		gen.Force = newForce
+		if pack != nil {
+			gen.Pack = pack
+			gen.Values = pack.Values(templateValues)
 		}
 
-		gen := generator.NewProjectGenerator(projectPath, projectName, moduleName, goVersion, dbType, storageType, deployType, telemetry)
-		if err := gen.Generate(); err != nil {
+		files, err := gen.ProjectFiles()
+		if err != nil {
+			return fmt.Errorf("failed to generate project: %w", err)
+		}
+
+		if outPath != "" {
+			if dryRun {
+				printDryRun(out, outPath+":"+projectName, files, nil)
+				return nil
+			}
+			if err := writeArchive(outPath, gen, files); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "Successfully created project '%s' in %s (Database: %s, Storage: %s, Deploy: %s)\n", projectName, outPath, dbType, storageType, deployType)
+			return nil
+		}
+
+		overwrites, err := gen.Overwrites(files)
+		if err != nil {
+			return err
+		}
+
+		if dryRun {
+			printDryRun(out, projectPath, files, overwrites)
+			return nil
+		}
+
+		for _, change := range overwrites {
+			fmt.Fprintf(out, "⚠️  %s already exists and would change:\n", change.File.Path)
+			printDiff(out, change)
+		}
+		if len(overwrites) > 0 && !newForce {
+			return fmt.Errorf("❌ refusing to overwrite %d existing file(s) in %s; re-run with --force to overwrite them", len(overwrites), projectPath)
+		}
+
+		if err := gen.Write(files); err != nil {
 			return fmt.Errorf("failed to generate project: %w", err)
 		}
 
-		fmt.Printf("Successfully created project '%s' at %s (Database: %s, Storage: %s, Deploy: %s)\n", projectName, projectPath, dbType, storageType, deployType)
-		fmt.Println("\nNext steps:")
-		fmt.Printf("  cd %s\n", projectName)
-		fmt.Println("  go mod tidy")
+		fmt.Fprintf(out, "Successfully created project '%s' at %s (Database: %s, Storage: %s, Deploy: %s)\n", projectName, projectPath, dbType, storageType, deployType)
+		fmt.Fprintln(out, "\nNext steps:")
+		fmt.Fprintf(out, "  cd %s\n", projectName)
+		fmt.Fprintln(out, "  go mod tidy")
 		if deployType == "lambda" {
-			fmt.Println("  ginboot build")
-			fmt.Println("  ginboot deploy")
+			fmt.Fprintln(out, "  ginboot build")
+			fmt.Fprintln(out, "  ginboot deploy")
+		} else if dbType != "none" {
+			fmt.Fprintln(out, "  ginboot dev --compose")
 		} else {
-			fmt.Println("  go run main.go")
+			fmt.Fprintln(out, "  ginboot dev")
 		}
 
 		return nil
 	},
 }
 
-// isValidProjectName checks if the project name contains only letters and numbers
-func isValidProjectName(name string) bool {
-	matched, _ := regexp.MatchString("^[a-zA-Z0-9]+$", name)
-	return matched
+// writeArchive generates the project into a zip or tar.gz archive at path,
+// with every file below a directory named after the project. The archive
+// only appears once it is complete.
+func writeArchive(path string, gen *generator.ProjectGenerator, files []generator.File) error {
+	if _, err := os.Stat(path); err == nil && !newForce {
+		return fmt.Errorf("❌ %s already exists; re-run with --force to overwrite it", path)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	switch {
+	case strings.HasSuffix(path, ".zip"):
+		gen.Output = generator.ZipWriter{W: tmp, Prefix: gen.ProjectName}
+	default:
+		gen.Output = generator.TarGzWriter{W: tmp, Prefix: gen.ProjectName}
+	}
+	if err := gen.Write(files); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to generate project: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// isArchive reports whether path names a supported archive format.
+func isArchive(path string) bool {
+	for _, ext := range []string{".zip", ".tar.gz", ".tgz"} {
+		if strings.HasSuffix(path, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+func packName(pack *generator.TemplatePack) string {
+	if pack.Name == "" {
+		return pack.Source
+	}
+	return fmt.Sprintf("%s (%s)", pack.Name, pack.Source)
 }
 
 func init() {
@@ -113,4 +238,13 @@ func init() {
 	newCmd.Flags().StringVar(&storageType, "storage", "", "Storage type: none, s3")
 	newCmd.Flags().StringVar(&deployType, "deploy", "", "Deployment type: http, lambda")
 	newCmd.Flags().BoolVar(&telemetry, "telemetry", false, "Enable OpenTelemetry support")
+	newCmd.Flags().StringVar(&newArch, "arch", "", "Lambda architecture: x86_64, arm64 (default: x86_64)")
+	newCmd.Flags().StringVar(&newRuntime, "runtime", "", "Lambda runtime: provided.al2, provided.al2023 (default: provided.al2)")
+	newCmd.Flags().StringVar(&ginbootVersion, "ginboot-version", "", "Ginboot framework version (default: $GINBOOT_VERSION or the latest release)")
+	newCmd.Flags().BoolVar(&offline, "offline", false, "Never access the network; use the cached or built-in Ginboot version")
+	newCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the files that would be generated without writing them")
+	newCmd.Flags().StringVar(&outPath, "out", "", "Write the project to a .zip, .tar.gz or .tgz archive instead of a directory")
+	newCmd.Flags().BoolVar(&newForce, "force", false, "Overwrite existing files that differ from the generated ones")
+	newCmd.Flags().StringVar(&templatePack, "template", "", "Template pack to generate from: a directory or git URL (append #ref for a branch or tag)")
+	newCmd.Flags().StringToStringVar(&templateValues, "set", nil, "Answer a template pack prompt without asking (e.g. --set team=payments)")
 }
@@ -0,0 +1,325 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/klass-lk/ginboot-cli/internal/runner"
+)
+
+// changeSetPattern matches the changeset ARN printed by
+// `sam deploy --no-execute-changeset`.
+var changeSetPattern = regexp.MustCompile(`arn:aws[a-z-]*:cloudformation:[^\s]+:changeSet/[^\s]+`)
+
+// ResourceChange is a resource change of a CloudFormation changeset.
+type ResourceChange struct {
+	Action       string `json:"action"` // Add, Modify, Remove, Import or Dynamic
+	LogicalID    string `json:"logical_id"`
+	PhysicalID   string `json:"physical_id,omitempty"`
+	ResourceType string `json:"resource_type"`
+	Replacement  string `json:"replacement,omitempty"` // True, False or Conditional; Modify only
+}
+
+// Replaces reports whether the change may replace the resource.
+func (c ResourceChange) Replaces() bool {
+	return c.Replacement == "True" || c.Replacement == "Conditional"
+}
+
+// DeployPlan is the JSON form of `ginboot deploy --plan=json`.
+type DeployPlan struct {
+	StackName string           `json:"stack_name"`
+	Region    string           `json:"region"`
+	ChangeSet string           `json:"change_set"`
+	Changes   []ResourceChange `json:"changes"`
+	Summary   PlanSummary      `json:"summary"`
+}
+
+// PlanSummary counts the changes of a plan by action.
+type PlanSummary struct {
+	Add     int `json:"add"`
+	Modify  int `json:"modify"`
+	Remove  int `json:"remove"`
+	Replace int `json:"replace"`
+}
+
+func summarize(changes []ResourceChange) PlanSummary {
+	var s PlanSummary
+	for _, c := range changes {
+		switch c.Action {
+		case "Add":
+			s.Add++
+		case "Modify":
+			s.Modify++
+		case "Remove":
+			s.Remove++
+		}
+		if c.Replaces() {
+			s.Replace++
+		}
+	}
+	return s
+}
+
+// changeSetARN returns the changeset ARN from the output of sam deploy.
+func changeSetARN(samOutput string) (string, error) {
+	arn := changeSetPattern.FindString(samOutput)
+	if arn == "" {
+		return "", fmt.Errorf("no changeset ARN in the output of sam deploy")
+	}
+	return arn, nil
+}
+
+// describeChangeSet is the subset of `aws cloudformation describe-change-set`
+// output the plan needs.
+type describeChangeSet struct {
+	Status       string
+	StatusReason string
+	NextToken    string
+	Changes      []struct {
+		Type           string
+		ResourceChange struct {
+			Action             string
+			LogicalResourceId  string
+			PhysicalResourceId string
+			ResourceType       string
+			Replacement        string
+		}
+	}
+}
+
+// parseChangeSet decodes one page of describe-change-set output and returns
+// its resource changes and the token of the next page.
+func parseChangeSet(data []byte) ([]ResourceChange, string, error) {
+	var out describeChangeSet
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, "", fmt.Errorf("failed to parse changeset: %w", err)
+	}
+	if out.Status == "FAILED" {
+		return nil, "", fmt.Errorf("changeset failed: %s", out.StatusReason)
+	}
+
+	var changes []ResourceChange
+	for _, c := range out.Changes {
+		if c.Type != "Resource" {
+			continue
+		}
+		rc := c.ResourceChange
+		changes = append(changes, ResourceChange{
+			Action:       rc.Action,
+			LogicalID:    rc.LogicalResourceId,
+			PhysicalID:   rc.PhysicalResourceId,
+			ResourceType: rc.ResourceType,
+			Replacement:  rc.Replacement,
+		})
+	}
+	return changes, out.NextToken, nil
+}
+
+// loadChangeSet describes every page of a changeset with the AWS CLI.
+func loadChangeSet(ctx context.Context, arn, region string, env []string) ([]ResourceChange, error) {
+	var changes []ResourceChange
+	token := ""
+	for {
+		args := []string{"cloudformation", "describe-change-set", "--change-set-name", arn, "--region", region, "--output", "json"}
+		if token != "" {
+			args = append(args, "--next-token", token)
+		}
+		output, err := runAWS(ctx, env, args...)
+		if err != nil {
+			return nil, err
+		}
+
+		page, next, err := parseChangeSet(output)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, page...)
+		if next == "" {
+			return changes, nil
+		}
+		token = next
+	}
+}
+
+// runAWS runs the AWS CLI and returns its stdout; stderr is included in the
+// error.
+func runAWS(ctx context.Context, env []string, args ...string) ([]byte, error) {
+	output, err := runner.Output(ctx, tools, runner.Command{Name: "aws", Args: args, Env: env})
+	if err != nil {
+		return nil, fmt.Errorf("aws %s failed: %w", strings.Join(args[:2], " "), err)
+	}
+	return output, nil
+}
+
+// printPlan prints the changes as a table.
+func printPlan(w io.Writer, changes []ResourceChange) {
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "No resource changes.")
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  ACTION\tLOGICAL ID\tTYPE\tREPLACEMENT")
+	for _, c := range changes {
+		replacement := ""
+		switch c.Replacement {
+		case "True":
+			replacement = "⚠️  yes"
+		case "Conditional":
+			replacement = "⚠️  conditional"
+		case "False":
+			replacement = "no"
+		}
+		fmt.Fprintf(tw, "%s %s\t%s\t%s\t%s\n", actionSymbol(c.Action), c.Action, c.LogicalID, c.ResourceType, replacement)
+	}
+	tw.Flush()
+
+	s := summarize(changes)
+	fmt.Fprintf(w, "\nPlan: %d to add, %d to modify, %d to remove.\n", s.Add, s.Modify, s.Remove)
+	if s.Replace > 0 {
+		fmt.Fprintf(w, "⚠️  %d resource(s) may be replaced; their data and physical IDs may not survive the update.\n", s.Replace)
+	}
+}
+
+func actionSymbol(action string) string {
+	switch action {
+	case "Add":
+		return "+"
+	case "Modify":
+		return "~"
+	case "Remove":
+		return "-"
+	}
+	return " "
+}
+
+// planDeploy creates a changeset with sam deploy, shows its changes on out
+// and executes it once confirmed. With --plan=json the plan is written to
+// jsonOut as JSON and the changeset is discarded.
+func planDeploy(ctx context.Context, out, jsonOut io.Writer, projectName string, config DeployConfig, deployArgs, env []string, interactive bool) error {
+	fmt.Fprintln(out, "\n🔍 Creating changeset...")
+	var output bytes.Buffer
+	err := tools.Run(ctx, runner.Command{Name: "sam", Args: deployArgs, Env: env, Stdout: &output, Stderr: &output})
+	if err != nil {
+		if !strings.Contains(output.String(), "No changes to deploy") {
+			fmt.Fprint(out, output.String())
+			return fmt.Errorf("❌ Failed to create changeset: %w", err)
+		}
+		if deployPlan == "json" {
+			return writePlanJSON(jsonOut, DeployPlan{StackName: config.StackName, Region: config.Region, Changes: []ResourceChange{}})
+		}
+		fmt.Fprintf(out, "\n✨ Stack %s is up to date. No changes to deploy.\n", config.StackName)
+		return nil
+	}
+
+	arn, err := changeSetARN(output.String())
+	if err != nil {
+		fmt.Fprint(out, output.String())
+		return fmt.Errorf("❌ %w", err)
+	}
+	changes, err := loadChangeSet(ctx, arn, config.Region, env)
+	if err != nil {
+		return fmt.Errorf("❌ Failed to read changeset: %w", err)
+	}
+
+	if deployPlan == "json" {
+		plan := DeployPlan{StackName: config.StackName, Region: config.Region, ChangeSet: arn, Changes: changes, Summary: summarize(changes)}
+		if plan.Changes == nil {
+			plan.Changes = []ResourceChange{}
+		}
+		if err := writePlanJSON(jsonOut, plan); err != nil {
+			return err
+		}
+		return discardChangeSet(ctx, out, config, arn, env)
+	}
+
+	fmt.Fprintf(out, "\n📋 Changes to %s (%s):\n\n", config.StackName, config.Region)
+	printPlan(out, changes)
+	fmt.Fprintln(out)
+
+	switch {
+	case assumeYes:
+	case interactive:
+		confirm := promptUser(out, "Do you want to execute this changeset? (y/N)", "N")
+		if !strings.EqualFold(confirm, "y") {
+			if err := discardChangeSet(ctx, out, config, arn, env); err != nil {
+				return err
+			}
+			return fmt.Errorf("❌ Deployment cancelled")
+		}
+	default:
+		fmt.Fprintln(out, "ℹ️  Not executing the changeset without --yes")
+		return discardChangeSet(ctx, out, config, arn, env)
+	}
+
+	fmt.Fprintln(out, "🔨 Executing changeset...")
+	status, err := stackStatus(ctx, config, env)
+	if err != nil {
+		return fmt.Errorf("❌ Deployment failed: %w", err)
+	}
+	// A stack created by its first changeset waits for it in REVIEW_IN_PROGRESS
+	wait := "stack-update-complete"
+	if status == "REVIEW_IN_PROGRESS" {
+		wait = "stack-create-complete"
+	}
+	if _, err := runAWS(ctx, env, "cloudformation", "execute-change-set", "--change-set-name", arn, "--region", config.Region); err != nil {
+		return fmt.Errorf("❌ Deployment failed: %w", err)
+	}
+	if _, err := runAWS(ctx, env, "cloudformation", "wait", wait, "--stack-name", config.StackName, "--region", config.Region); err != nil {
+		return fmt.Errorf("❌ Deployment failed: %w", err)
+	}
+
+	fmt.Fprintf(out, "\n✨ Successfully deployed %s!\n", projectName)
+	printEndpoint(ctx, out, projectName, config, env)
+	return nil
+}
+
+// writePlanJSON writes the plan to w.
+func writePlanJSON(w io.Writer, plan DeployPlan) error {
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+// stackStatus returns the status of the stack, e.g. UPDATE_COMPLETE.
+func stackStatus(ctx context.Context, config DeployConfig, env []string) (string, error) {
+	status, err := runAWS(ctx, env, "cloudformation", "describe-stacks", "--stack-name", config.StackName, "--region", config.Region,
+		"--query", "Stacks[0].StackStatus", "--output", "text")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(status)), nil
+}
+
+// discardChangeSet deletes a changeset that won't be executed. A stack that
+// was created for the changeset, and is left empty in REVIEW_IN_PROGRESS, is
+// deleted too so that the next deploy can create it again.
+func discardChangeSet(ctx context.Context, out io.Writer, config DeployConfig, arn string, env []string) error {
+	if _, err := runAWS(ctx, env, "cloudformation", "delete-change-set", "--change-set-name", arn, "--region", config.Region); err != nil {
+		return fmt.Errorf("❌ Failed to delete changeset: %w", err)
+	}
+	fmt.Fprintln(out, "🗑️  Changeset discarded")
+
+	status, err := stackStatus(ctx, config, env)
+	if err != nil {
+		fmt.Fprintf(out, "⚠️  Could not check whether stack %s is left empty: %v\n", config.StackName, err)
+		return nil
+	}
+	if status != "REVIEW_IN_PROGRESS" {
+		return nil
+	}
+	if _, err := runAWS(ctx, env, "cloudformation", "delete-stack", "--stack-name", config.StackName, "--region", config.Region); err != nil {
+		return fmt.Errorf("❌ Failed to delete the empty stack %s: %w", config.StackName, err)
+	}
+	fmt.Fprintf(out, "🗑️  Deleted the empty stack %s created for the changeset\n", config.StackName)
+	return nil
+}
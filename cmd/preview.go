@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/klass-lk/ginboot-cli/internal/diff"
+	"github.com/klass-lk/ginboot-cli/internal/generator"
+)
+
+// printDiff prints a unified diff from a file's current content to the
+// planned content.
+func printDiff(out io.Writer, change generator.Change) {
+	fmt.Fprint(out, diff.Unified("a/"+change.File.Path, "b/"+change.File.Path, change.Existing, change.File.Content))
+	fmt.Fprintln(out)
+}
+
+// printDryRun prints the tree and contents of files that would be written
+// below root. Files that already exist with different content are shown as
+// a diff instead.
+func printDryRun(out io.Writer, root string, files []generator.File, overwrites []generator.Change) {
+	changed := map[string]generator.Change{}
+	for _, change := range overwrites {
+		changed[change.File.Path] = change
+	}
+
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		paths = append(paths, file.Path)
+	}
+
+	fmt.Fprintf(out, "🔍 Dry run: %d file(s) would be written to %s\n\n", len(files), root)
+	fmt.Fprintln(out, filepath.ToSlash(filepath.Clean(root))+"/")
+	printTree(out, newTree(paths), "", changed)
+
+	for _, file := range files {
+		fmt.Fprintln(out)
+		if change, ok := changed[file.Path]; ok {
+			fmt.Fprintf(out, "── %s (would change) ──\n", file.Path)
+			printDiff(out, change)
+			continue
+		}
+		fmt.Fprintf(out, "── %s ──\n", file.Path)
+		fmt.Fprint(out, string(file.Content))
+		if !strings.HasSuffix(string(file.Content), "\n") {
+			fmt.Fprintln(out)
+		}
+	}
+
+	if len(overwrites) > 0 {
+		fmt.Fprintf(out, "\n⚠️  %d existing file(s) would be overwritten; --force is required\n", len(overwrites))
+	}
+}
+
+// tree is a directory of a file tree; files are leaves without children.
+type tree struct {
+	name     string
+	path     string
+	children map[string]*tree
+}
+
+func newTree(paths []string) *tree {
+	root := &tree{children: map[string]*tree{}}
+	for _, path := range paths {
+		node := root
+		parts := strings.Split(path, "/")
+		for i, part := range parts {
+			child, ok := node.children[part]
+			if !ok {
+				child = &tree{name: part, path: strings.Join(parts[:i+1], "/")}
+				if i < len(parts)-1 {
+					child.children = map[string]*tree{}
+				}
+				node.children[part] = child
+			}
+			node = child
+		}
+	}
+	return root
+}
+
+func printTree(out io.Writer, node *tree, indent string, changed map[string]generator.Change) {
+	names := make([]string, 0, len(node.children))
+	for name := range node.children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for i, name := range names {
+		child := node.children[name]
+		branch, next := "├── ", "│   "
+		if i == len(names)-1 {
+			branch, next = "└── ", "    "
+		}
+
+		label := child.name
+		if child.children != nil {
+			label += "/"
+		} else if _, ok := changed[child.path]; ok {
+			label += "  (would change)"
+		}
+		fmt.Fprintln(out, indent+branch+label)
+
+		if child.children != nil {
+			printTree(out, child, indent+next, changed)
+		}
+	}
+}
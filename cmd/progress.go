@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mattn/go-isatty"
+)
+
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// progressInterval is how often a phase reports progress when its output is
+// not a terminal, such as a CI log.
+var progressInterval = 10 * time.Second
+
+// phase reports a step of a long-running command. Tool output written to it
+// is streamed when verbose, and otherwise kept to be shown if the step fails;
+// a spinner (on a terminal) or periodic progress lines show the elapsed time
+// and the latest output line.
+type phase struct {
+	name    string
+	out     io.Writer
+	verbose bool
+	start   time.Time
+
+	mu     sync.Mutex
+	output bytes.Buffer
+	last   string
+
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// startPhase starts a phase reporting to out.
+func startPhase(out io.Writer, name string, verbose bool) *phase {
+	p := &phase{name: name, out: out, verbose: verbose, start: time.Now()}
+	if verbose {
+		fmt.Fprintf(out, "🔨 %s...\n", name)
+		return p
+	}
+
+	p.stop = make(chan struct{})
+	p.stopped = make(chan struct{})
+	if isTerminal(out) {
+		go p.spin()
+	} else {
+		fmt.Fprintf(out, "🔨 %s...\n", name)
+		go p.tick()
+	}
+	return p
+}
+
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && (isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd()))
+}
+
+func (p *phase) spin() {
+	defer close(p.stopped)
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for frame := 0; ; frame++ {
+		p.mu.Lock()
+		last := p.last
+		p.mu.Unlock()
+		fmt.Fprintf(p.out, "\r\033[K%s %s %s  %s", spinnerFrames[frame%len(spinnerFrames)], p.name, formatDuration(time.Since(p.start)), truncate(last, 60))
+
+		select {
+		case <-p.stop:
+			fmt.Fprint(p.out, "\r\033[K")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// tick prints a progress line every progressInterval, so logs without a
+// spinner still show that the phase is running.
+func (p *phase) tick() {
+	defer close(p.stopped)
+	ticker := time.NewTicker(progressInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+		}
+
+		p.mu.Lock()
+		last := p.last
+		p.mu.Unlock()
+		line := fmt.Sprintf("⏳ %s %s", p.name, formatDuration(time.Since(p.start)))
+		if last != "" {
+			line += "  " + truncate(last, 60)
+		}
+		fmt.Fprintln(p.out, line)
+	}
+}
+
+func (p *phase) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.verbose {
+		return p.out.Write(b)
+	}
+	p.output.Write(b)
+	lines := strings.Split(strings.TrimRight(string(b), "\r\n"), "\n")
+	if last := strings.TrimSpace(lines[len(lines)-1]); last != "" {
+		p.last = last
+	}
+	return len(b), nil
+}
+
+// end stops the phase, reports how it went and returns its duration. The
+// kept output is shown when err is set.
+func (p *phase) end(err error) time.Duration {
+	elapsed := time.Since(p.start)
+	if p.stop != nil {
+		close(p.stop)
+		<-p.stopped
+	}
+
+	if err != nil {
+		fmt.Fprintf(p.out, "❌ %s failed (%s)\n", p.name, formatDuration(elapsed))
+		if !p.verbose {
+			p.mu.Lock()
+			p.out.Write(p.output.Bytes())
+			p.mu.Unlock()
+		}
+		return elapsed
+	}
+	fmt.Fprintf(p.out, "✅ %s (%s)\n", p.name, formatDuration(elapsed))
+	return elapsed
+}
+
+// truncate shortens s to at most n runes, ending it with "..." when cut.
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:n-3]) + "..."
+}
+
+// formatDuration formats a duration for humans, e.g. "12.3s" or "40ms".
+func formatDuration(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(100 * time.Millisecond).String()
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/klass-lk/ginboot-cli/internal/generator"
+	"github.com/klass-lk/ginboot-cli/internal/manifest"
+)
+
+// loadProject loads the ginboot.yaml manifest from the current directory.
+// Projects generated before the manifest existed fall back to what can be
+// inferred from go.mod and the directory name.
+func loadProject() (*manifest.Manifest, error) {
+	m, err := manifest.Load(".")
+	if err == nil {
+		return m, nil
+	}
+	if !errors.Is(err, manifest.ErrNotFound) {
+		return nil, err
+	}
+
+	currentDir, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	m = &manifest.Manifest{
+		ProjectName: filepath.Base(currentDir),
+		Database:    "none",
+		Storage:     "none",
+		Deploy:      "http",
+	}
+	if err := detectProject("go.mod", m); err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat("template.yaml"); err == nil {
+		m.Deploy = "lambda"
+	}
+
+	return m, nil
+}
+
+// projectGenerator returns a generator for the project in the current
+// directory, loading the template pack it was generated from, if any, at
+// the commit it was generated from.
+func projectGenerator(ctx context.Context, m *manifest.Manifest, offline bool) (*generator.ProjectGenerator, error) {
+	gen := generator.NewProjectGeneratorFromManifest(".", m)
+	if m.Template == nil {
+		return gen, nil
+	}
+
+	pack, err := generator.LoadPinnedTemplatePack(ctx, m.Template.Source, m.Template.Commit, offline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load template pack: %w", err)
+	}
+	gen.Pack = pack
+
+	return gen, nil
+}
+
+// detectProject reads the module path from go.mod and infers the database,
+// storage and telemetry options from the Ginboot modules it requires.
+func detectProject(goModPath string, m *manifest.Manifest) error {
+	f, err := os.Open(goModPath)
+	if err != nil {
+		return fmt.Errorf("❌ go.mod not found. Run this command from the root of a Ginboot project")
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "module "):
+			m.ModuleName = strings.TrimSpace(strings.TrimPrefix(line, "module "))
+		case strings.HasPrefix(line, "go "):
+			m.GoVersion = strings.TrimSpace(strings.TrimPrefix(line, "go "))
+		case strings.HasPrefix(line, "github.com/klass-lk/ginboot "):
+			m.GinbootVersion = strings.TrimSpace(strings.TrimPrefix(line, "github.com/klass-lk/ginboot "))
+		case strings.Contains(line, "github.com/klass-lk/ginboot/db/mongo"):
+			m.Database = "mongodb"
+		case strings.Contains(line, "github.com/klass-lk/ginboot/db/dynamodb"):
+			m.Database = "dynamodb"
+		case strings.Contains(line, "github.com/lib/pq"):
+			m.Database = "postgres"
+		case strings.Contains(line, "github.com/go-sql-driver/mysql"):
+			m.Database = "mysql"
+		case strings.Contains(line, "github.com/klass-lk/ginboot/storage/s3"):
+			m.Storage = "s3"
+		case strings.Contains(line, "github.com/klass-lk/ginboot/runtime/lambda"):
+			m.Deploy = "lambda"
+		case strings.Contains(line, "github.com/klass-lk/ginboot/telemetry"):
+			m.Telemetry = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read go.mod: %w", err)
+	}
+	if m.ModuleName == "" {
+		return fmt.Errorf("❌ module directive not found in go.mod")
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/klass-lk/ginboot-cli/internal/manifest"
+	"github.com/klass-lk/ginboot-cli/internal/runner"
+)
+
+func TestLoadProjectFromGoMod(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "orders")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+
+	if _, err := loadProject(); err == nil {
+		t.Error("loadProject() succeeded without ginboot.yaml or go.mod")
+	}
+
+	goMod := `module github.com/acme/orders
+
+go 1.22
+
+require (
+	github.com/klass-lk/ginboot v1.14.2
+	github.com/klass-lk/ginboot/db/mongo v1.14.2
+	github.com/klass-lk/ginboot/storage/s3 v1.14.2
+)
+`
+	if err := os.WriteFile("go.mod", []byte(goMod), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m, err := loadProject()
+	if err != nil {
+		t.Fatalf("loadProject() error = %v", err)
+	}
+	if m.ProjectName != "orders" || m.ModuleName != "github.com/acme/orders" || m.GoVersion != "1.22" || m.GinbootVersion != "v1.14.2" {
+		t.Errorf("loadProject() = %+v", m)
+	}
+	if m.Database != "mongodb" || m.Storage != "s3" || m.Deploy != "http" || m.Telemetry {
+		t.Errorf("loadProject() stack = %s, %s, %s, %t", m.Database, m.Storage, m.Deploy, m.Telemetry)
+	}
+
+	if err := os.WriteFile("template.yaml", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if m, err := loadProject(); err != nil || m.Deploy != "lambda" {
+		t.Errorf("loadProject() with template.yaml = %+v, %v, want the lambda deploy target", m, err)
+	}
+}
+
+func TestLoadProjectManifest(t *testing.T) {
+	newProject(t, "")
+	if err := os.WriteFile("go.mod", []byte("module example.com/other\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := loadProject()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.ModuleName != "example.com/demo" {
+		t.Errorf("loadProject() module = %q, want the one in ginboot.yaml", m.ModuleName)
+	}
+}
+
+func TestLambdaCommandsRejectHTTPProjects(t *testing.T) {
+	dir := newProject(t, "stack_name: demo\nregion: us-east-1\nuse_default_bucket: true\n")
+	m := &manifest.Manifest{ProjectName: "demo", ModuleName: "example.com/demo", Database: "none", Storage: "none", Deploy: "http"}
+	if err := m.Save(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, args := range [][]string{{"build"}, {"build", "--native"}, {"deploy", "--no-input", "--yes"}} {
+		rec := &runner.Recorder{}
+		err := execute(t, rec, args...)
+		if err == nil || !strings.Contains(err.Error(), "only supports lambda projects") {
+			t.Errorf("%s error = %v, want the http project rejected", strings.Join(args, " "), err)
+		}
+		if lines := rec.Lines(); len(lines) > 0 {
+			t.Errorf("%s ran %q for an http project", strings.Join(args, " "), lines)
+		}
+	}
+}
@@ -1,9 +1,13 @@
 package cmd
 
 import (
+	"github.com/klass-lk/ginboot-cli/internal/runner"
 	"github.com/spf13/cobra"
 )
 
+// tools runs sam, aws and go; tests replace it with a runner.Recorder.
+var tools runner.Runner = runner.Exec{}
+
 var rootCmd = &cobra.Command{
 	Use:   "ginboot",
 	Short: "Ginboot CLI - A tool for managing Ginboot projects",
@@ -17,8 +21,16 @@ func Execute() error {
 
 func init() {
 	rootCmd.AddCommand(newCmd)
+	rootCmd.AddCommand(generateCmd)
+	rootCmd.AddCommand(addCmd)
+	rootCmd.AddCommand(devCmd)
 	rootCmd.AddCommand(buildCmd)
 	rootCmd.AddCommand(deployCmd)
+	rootCmd.AddCommand(destroyCmd)
+	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(logsCmd)
+	rootCmd.AddCommand(invokeCmd)
+	rootCmd.AddCommand(localAPICmd)
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(updateCmd)
 }
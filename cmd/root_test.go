@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/klass-lk/ginboot-cli/internal/manifest"
+	"github.com/klass-lk/ginboot-cli/internal/runner"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// newProject changes into a new lambda project directory with the given
+// ginboot-app.yml, if any.
+func newProject(t *testing.T, appConfig string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	t.Chdir(dir)
+	m := &manifest.Manifest{ProjectName: "demo", ModuleName: "example.com/demo", Database: "none", Storage: "none", Deploy: "lambda"}
+	if err := m.Save(dir); err != nil {
+		t.Fatal(err)
+	}
+	if appConfig != "" {
+		if err := os.WriteFile(filepath.Join(dir, "ginboot-app.yml"), []byte(appConfig), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// execute runs the CLI with args, running external tools with r.
+func execute(t *testing.T, r runner.Runner, args ...string) error {
+	t.Helper()
+
+	saved := tools
+	tools = r
+	t.Cleanup(func() { tools = saved })
+
+	resetFlags(rootCmd)
+	rootCmd.SetArgs(args)
+	rootCmd.SilenceUsage = true
+	rootCmd.SilenceErrors = true
+	return rootCmd.Execute()
+}
+
+// executeOutput runs the CLI like execute and returns what it wrote to
+// stdout and stderr.
+func executeOutput(t *testing.T, r runner.Runner, args ...string) (stdout, stderr string, err error) {
+	t.Helper()
+
+	var out, errOut bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetErr(&errOut)
+	t.Cleanup(func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetErr(nil)
+	})
+	err = execute(t, r, args...)
+	return out.String(), errOut.String(), err
+}
+
+// resetFlags restores the defaults of every flag, which cobra keeps in
+// package variables between runs.
+func resetFlags(c *cobra.Command) {
+	// Once set, key=value flags merge into their map instead of replacing it
+	for _, m := range []map[string]string{parameterOverrides, tags, templateValues} {
+		clear(m)
+	}
+	resetFlagSet(c)
+}
+
+func resetFlagSet(c *cobra.Command) {
+	c.Flags().VisitAll(func(f *pflag.Flag) {
+		if fresh, ok := f.Value.(*freshSlice); ok {
+			f.Value = fresh.Value
+		}
+		switch value := f.Value.(type) {
+		case pflag.SliceValue:
+			var def []string
+			if trimmed := strings.Trim(f.DefValue, "[]"); trimmed != "" {
+				def = strings.Split(trimmed, ",")
+			}
+			value.Replace(def)
+			f.Value = &freshSlice{Value: f.Value}
+		default:
+			if f.Value.Type() != "stringToString" {
+				f.Value.Set(f.DefValue)
+			}
+		}
+		f.Changed = false
+	})
+	for _, sub := range c.Commands() {
+		resetFlagSet(sub)
+	}
+}
+
+// freshSlice makes the first Set of a run replace the default, like it does
+// in a new process; pflag appends once a slice flag was set before.
+type freshSlice struct {
+	pflag.Value
+	set bool
+}
+
+func (v *freshSlice) Set(s string) error {
+	if !v.set {
+		v.set = true
+		v.Value.(pflag.SliceValue).Replace(nil)
+	}
+	return v.Value.Set(s)
+}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	statusEnv    string
+	statusConfig string
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the state and outputs of the deployed stack",
+	Long: `Show the CloudFormation state of the stack created by ginboot deploy and its outputs,
+such as the API endpoint. The stack is read from ginboot-app.yml (or --config), for the
+environment chosen with --env.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := cmd.OutOrStdout()
+		project, err := loadProject()
+		if err != nil {
+			return err
+		}
+		if !project.HasLambda() {
+			return fmt.Errorf("❌ %s is configured for the %s deploy target; ginboot status only supports lambda projects", project.ProjectName, project.Deploy)
+		}
+		if _, err := tools.LookPath("aws"); err != nil {
+			return fmt.Errorf("❌ AWS CLI is not installed. Please install it first: https://docs.aws.amazon.com/cli/latest/userguide/getting-started-install.html")
+		}
+
+		_, config, env, environ, err := deployedStack(statusConfig, statusEnv)
+		if err != nil {
+			return err
+		}
+		stack, err := describeStack(cmd.Context(), config, environ)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(out, "📊 Stack %s (%s)\n", config.StackName, config.Region)
+		if env != "" {
+			fmt.Fprintf(out, "  Environment: %s\n", env)
+		}
+		fmt.Fprintf(out, "  Status: %s %s\n", stackStatusIcon(stack.StackStatus), stack.StackStatus)
+		if stack.StackStatusReason != "" {
+			fmt.Fprintf(out, "  Reason: %s\n", stack.StackStatusReason)
+		}
+		fmt.Fprintf(out, "  Last updated: %s\n", firstNonEmpty(stack.LastUpdatedTime, stack.CreationTime))
+
+		if len(stack.Outputs) > 0 {
+			fmt.Fprintln(out, "\nOutputs:")
+			tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			for _, output := range stack.Outputs {
+				fmt.Fprintf(tw, "  %s\t%s\t%s\n", output.OutputKey, output.OutputValue, output.Description)
+			}
+			tw.Flush()
+		}
+		if endpoint := stack.Output(project.ProjectName + "Endpoint"); endpoint != "" {
+			fmt.Fprintf(out, "\n🌐 Endpoint: %s\n", endpoint)
+		}
+
+		return nil
+	},
+}
+
+// cloudFormationStack is the subset of `aws cloudformation describe-stacks`
+// output the CLI uses.
+type cloudFormationStack struct {
+	StackStatus       string
+	StackStatusReason string
+	CreationTime      string
+	LastUpdatedTime   string
+	Outputs           []struct {
+		OutputKey   string
+		OutputValue string
+		Description string
+	}
+}
+
+// Output returns the value of the output with the given key, or "".
+func (s *cloudFormationStack) Output(key string) string {
+	for _, output := range s.Outputs {
+		if output.OutputKey == key {
+			return output.OutputValue
+		}
+	}
+	return ""
+}
+
+// describeStack reads the stack with the AWS CLI.
+func describeStack(ctx context.Context, config DeployConfig, env []string) (*cloudFormationStack, error) {
+	output, err := runAWS(ctx, env, "cloudformation", "describe-stacks", "--stack-name", config.StackName, "--region", config.Region, "--output", "json")
+	if err != nil {
+		if strings.Contains(err.Error(), "does not exist") {
+			return nil, fmt.Errorf("❌ stack %s does not exist in %s; deploy it with ginboot deploy", config.StackName, config.Region)
+		}
+		return nil, fmt.Errorf("❌ Failed to describe stack: %w", err)
+	}
+
+	var stacks struct {
+		Stacks []cloudFormationStack
+	}
+	if err := json.Unmarshal(output, &stacks); err != nil {
+		return nil, fmt.Errorf("❌ Failed to parse stack: %w", err)
+	}
+	if len(stacks.Stacks) == 0 {
+		return nil, fmt.Errorf("❌ stack %s does not exist in %s", config.StackName, config.Region)
+	}
+	return &stacks.Stacks[0], nil
+}
+
+// printEndpoint prints the API endpoint of a deployed stack. It is best
+// effort: nothing is printed without the AWS CLI or when the stack can't be
+// read.
+func printEndpoint(ctx context.Context, out io.Writer, projectName string, config DeployConfig, env []string) {
+	if _, err := tools.LookPath("aws"); err != nil {
+		return
+	}
+	stack, err := describeStack(ctx, config, env)
+	if err != nil {
+		return
+	}
+	if endpoint := stack.Output(projectName + "Endpoint"); endpoint != "" {
+		fmt.Fprintf(out, "🌐 Endpoint: %s\n", endpoint)
+	}
+}
+
+func stackStatusIcon(status string) string {
+	switch {
+	case strings.HasSuffix(status, "_FAILED") || strings.HasPrefix(status, "ROLLBACK") || strings.Contains(status, "_ROLLBACK_"):
+		return "❌"
+	case strings.HasSuffix(status, "_IN_PROGRESS"):
+		return "⏳"
+	case strings.HasSuffix(status, "_COMPLETE"):
+		return "✅"
+	}
+	return "ℹ️"
+}
+
+func init() {
+	statusCmd.Flags().StringVarP(&statusEnv, "env", "e", "", "Environment from ginboot-app.yml (default: default_environment)")
+	statusCmd.Flags().StringVar(&statusConfig, "config", "ginboot-app.yml", "Path of the deployment configuration file")
+}
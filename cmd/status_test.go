@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/klass-lk/ginboot-cli/internal/runner"
+)
+
+func TestStatus(t *testing.T) {
+	t.Setenv("PROFILE", "production")
+	newProject(t, `region: us-east-1
+default_environment: dev
+environments:
+  dev:
+    stack_name: demo-dev
+  prod:
+    stack_name: demo-prod
+    env:
+      AWS_PROFILE: ${PROFILE}
+`)
+
+	rec := &runner.Recorder{Handle: func(c runner.Command) error {
+		fmt.Fprint(c.Stdout, `{"Stacks": [{
+			"StackStatus": "UPDATE_COMPLETE",
+			"CreationTime": "2024-01-01T00:00:00Z",
+			"LastUpdatedTime": "2024-02-01T00:00:00Z",
+			"Outputs": [{"OutputKey": "demoEndpoint", "OutputValue": "https://abc.execute-api.us-east-1.amazonaws.com/Prod/", "Description": "API endpoint"}]
+		}]}`)
+		return nil
+	}}
+	stdout, _, err := executeOutput(t, rec, "status", "--env", "prod")
+	if err != nil {
+		t.Fatalf("status error = %v", err)
+	}
+
+	want := []string{"aws cloudformation describe-stacks --stack-name demo-prod --region us-east-1 --output json"}
+	if got := rec.Lines(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("ran %q, want %q", got, want)
+	}
+	if env := rec.Commands()[0].Env; !reflect.DeepEqual(env, []string{"AWS_PROFILE=production"}) {
+		t.Errorf("aws env = %q, want AWS_PROFILE=production", env)
+	}
+	for _, line := range []string{
+		"📊 Stack demo-prod (us-east-1)",
+		"  Environment: prod",
+		"  Status: ✅ UPDATE_COMPLETE",
+		"  Last updated: 2024-02-01T00:00:00Z",
+		"🌐 Endpoint: https://abc.execute-api.us-east-1.amazonaws.com/Prod/",
+	} {
+		if !strings.Contains(stdout, line+"\n") {
+			t.Errorf("output does not contain %q:\n%s", line, stdout)
+		}
+	}
+}
+
+func TestStatusMissingStack(t *testing.T) {
+	newProject(t, "stack_name: demo\nregion: us-east-1\n")
+
+	rec := &runner.Recorder{Handle: func(c runner.Command) error {
+		fmt.Fprintln(c.Stderr, "An error occurred (ValidationError) when calling the DescribeStacks operation: Stack with id demo does not exist")
+		return errors.New("exit status 254")
+	}}
+	stdout, _, err := executeOutput(t, rec, "status")
+	if err == nil || !strings.Contains(err.Error(), "stack demo does not exist in us-east-1") {
+		t.Errorf("status error = %v, want the missing stack reported", err)
+	}
+	if stdout != "" {
+		t.Errorf("status printed %q for a missing stack", stdout)
+	}
+}
@@ -1,26 +1,86 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
-	"os/exec"
+	"path/filepath"
+	"time"
 
+	"github.com/klass-lk/ginboot-cli/internal/config"
+	"github.com/klass-lk/ginboot-cli/internal/release"
+	"github.com/klass-lk/ginboot-cli/internal/runner"
 	"github.com/spf13/cobra"
 )
 
+const cliModule = "github.com/klass-lk/ginboot-cli"
+
 var updateCmd = &cobra.Command{
 	Use:   "update",
 	Short: "Update Ginboot CLI to the latest version",
+	Long: `Update Ginboot CLI to the latest version.
+
+The latest version is looked up from the configured release source (GitHub releases by
+default). With a goproxy or local directory source, 'go install' is pointed at the same
+proxy so air-gapped machines never reach the public internet.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("Updating ginboot-cli...")
-		c := exec.Command("go", "install", "github.com/klass-lk/ginboot-cli@latest")
-		c.Stdout = os.Stdout
-		c.Stderr = os.Stderr
-		err := c.Run()
+		out := cmd.OutOrStdout()
+		fmt.Fprintln(out, "Updating ginboot-cli...")
+
+		userConfig, err := config.Load()
+		if err != nil {
+			fmt.Fprintf(out, "Failed to update ginboot-cli: %v\n", err)
+			os.Exit(1)
+		}
+		source, err := userConfig.Source()
 		if err != nil {
-			fmt.Printf("Failed to update ginboot-cli: %v\n", err)
+			fmt.Fprintf(out, "Failed to update ginboot-cli: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Println("Successfully updated ginboot-cli to the latest version!")
+
+		version := "latest"
+		ctx, cancel := context.WithTimeout(cmd.Context(), 10*time.Second)
+		defer cancel()
+		if latest, err := source.Latest(ctx, cliModule); err != nil {
+			fmt.Fprintf(out, "⚠️  Could not look up the latest version from %s: %v\n", source, err)
+		} else {
+			version = latest
+		}
+
+		install := runner.Command{
+			Name:   "go",
+			Args:   []string{"install", cliModule + "@" + version},
+			Stdout: out,
+			Stderr: cmd.ErrOrStderr(),
+		}
+		if proxy := goProxyFor(source); proxy != "" {
+			install.Env = append(install.Env, "GOPROXY="+proxy)
+		}
+		err = tools.Run(cmd.Context(), install)
+		if err != nil {
+			fmt.Fprintf(out, "Failed to update ginboot-cli: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(out, "Successfully updated ginboot-cli to %s!\n", version)
 	},
 }
+
+// goProxyFor returns the GOPROXY value that serves modules from the same
+// place as source, or an empty string to keep the user's setting.
+func goProxyFor(source release.Source) string {
+	switch s := source.(type) {
+	case release.GoProxy:
+		return s.URL
+	case release.Local:
+		info, err := os.Stat(s.Path)
+		if err != nil || !info.IsDir() {
+			return ""
+		}
+		abs, err := filepath.Abs(s.Path)
+		if err != nil {
+			return ""
+		}
+		return "file://" + filepath.ToSlash(abs)
+	}
+	return ""
+}
@@ -11,11 +11,12 @@ var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Print the version number of Ginboot CLI",
 	Run: func(cmd *cobra.Command, args []string) {
+		out := cmd.OutOrStdout()
 		info, ok := debug.ReadBuildInfo()
 		if ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
-			fmt.Printf("ginboot-cli version %s\n", info.Main.Version)
+			fmt.Fprintf(out, "ginboot-cli version %s\n", info.Main.Version)
 		} else {
-			fmt.Println("ginboot-cli version (development)")
+			fmt.Fprintln(out, "ginboot-cli version (development)")
 		}
 	},
 }
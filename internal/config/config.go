@@ -0,0 +1,76 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/klass-lk/ginboot-cli/internal/release"
+	"gopkg.in/yaml.v2"
+)
+
+// FileName is the name of the user configuration file inside Dir.
+const FileName = "config.yaml"
+
+// ReleaseSourceEnv overrides the configured release source, e.g.
+// "goproxy:https://proxy.example.com" or "local:/srv/releases".
+const ReleaseSourceEnv = "GINBOOT_RELEASE_SOURCE"
+
+// Config is the user-level ginboot configuration.
+type Config struct {
+	ReleaseSource ReleaseSourceConfig `yaml:"release_source"`
+}
+
+// ReleaseSourceConfig selects where ginboot looks up released versions.
+type ReleaseSourceConfig struct {
+	Type string `yaml:"type"`           // github, goproxy or local
+	URL  string `yaml:"url,omitempty"`  // API or proxy base URL
+	Path string `yaml:"path,omitempty"` // File or directory for local
+}
+
+// Dir returns the ginboot configuration directory, e.g. ~/.config/ginboot.
+func Dir() (string, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate user config directory: %w", err)
+	}
+	return filepath.Join(base, "ginboot"), nil
+}
+
+// Load reads the user configuration. A missing file yields the defaults.
+func Load() (*Config, error) {
+	dir, err := Dir()
+	if err != nil {
+		return &Config{}, nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, FileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", FileName, err)
+	}
+
+	var c Config
+	if err := yaml.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", FileName, err)
+	}
+
+	return &c, nil
+}
+
+// Source returns the configured release source. GINBOOT_RELEASE_SOURCE takes
+// precedence over the configuration file.
+func (c *Config) Source() (release.Source, error) {
+	if spec := os.Getenv(ReleaseSourceEnv); spec != "" {
+		return release.Parse(spec)
+	}
+
+	location := c.ReleaseSource.URL
+	if c.ReleaseSource.Type == "local" {
+		location = c.ReleaseSource.Path
+	}
+	return release.New(c.ReleaseSource.Type, location)
+}
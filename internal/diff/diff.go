@@ -0,0 +1,157 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+const contextLines = 3
+
+type opKind int
+
+const (
+	opEqual opKind = iota
+	opDelete
+	opInsert
+)
+
+type op struct {
+	kind opKind
+	line string
+}
+
+// Unified returns a unified diff turning a into b, or an empty string when
+// they are equal.
+func Unified(oldName, newName string, a, b []byte) string {
+	if string(a) == string(b) {
+		return ""
+	}
+
+	ops := lineOps(splitLines(string(a)), splitLines(string(b)))
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", oldName, newName)
+
+	// Walk the edit script and emit hunks of changes with surrounding context.
+	for i := 0; i < len(ops); {
+		if ops[i].kind == opEqual {
+			i++
+			continue
+		}
+
+		start := i - contextLines
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != opEqual {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].kind == opEqual {
+				run++
+			}
+			if run == len(ops) || run-end > 2*contextLines {
+				end += min(contextLines, run-end)
+				break
+			}
+			end = run
+		}
+
+		oldStart, newStart := 1, 1
+		for _, o := range ops[:start] {
+			if o.kind != opInsert {
+				oldStart++
+			}
+			if o.kind != opDelete {
+				newStart++
+			}
+		}
+		oldCount, newCount := 0, 0
+		for _, o := range ops[start:end] {
+			if o.kind != opInsert {
+				oldCount++
+			}
+			if o.kind != opDelete {
+				newCount++
+			}
+		}
+
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount))
+		for _, o := range ops[start:end] {
+			switch o.kind {
+			case opEqual:
+				out.WriteString(" " + o.line + "\n")
+			case opDelete:
+				out.WriteString("-" + o.line + "\n")
+			case opInsert:
+				out.WriteString("+" + o.line + "\n")
+			}
+		}
+		i = end
+	}
+
+	return out.String()
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start-1)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// lineOps computes an edit script from the longest common subsequence of
+// the two line slices.
+func lineOps(a, b []string) []op {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var ops []op
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, op{opEqual, a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, op{opDelete, a[i]})
+			i++
+		default:
+			ops = append(ops, op{opInsert, b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, op{opDelete, a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, op{opInsert, b[j]})
+	}
+
+	return ops
+}
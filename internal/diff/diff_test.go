@@ -0,0 +1,66 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	lines := func(n int, change map[int]string) []byte {
+		var b strings.Builder
+		for i := 1; i <= n; i++ {
+			if line, ok := change[i]; ok {
+				if line != "" {
+					b.WriteString(line + "\n")
+				}
+				continue
+			}
+			b.WriteString("line " + string(rune('a'+i-1)) + "\n")
+		}
+		return []byte(b.String())
+	}
+
+	tests := []struct {
+		name string
+		a, b []byte
+		want string
+	}{
+		{
+			name: "equal",
+			a:    lines(3, nil),
+			b:    lines(3, nil),
+			want: "",
+		},
+		{
+			name: "new file",
+			a:    nil,
+			b:    []byte("one\ntwo\n"),
+			want: "--- a\n+++ b\n@@ -0,0 +1,2 @@\n+one\n+two\n",
+		},
+		{
+			name: "change with context",
+			a:    lines(10, nil),
+			b:    lines(10, map[int]string{5: "line E"}),
+			want: "--- a\n+++ b\n@@ -2,7 +2,7 @@\n line b\n line c\n line d\n-line e\n+line E\n line f\n line g\n line h\n",
+		},
+		{
+			name: "separate hunks",
+			a:    lines(12, nil),
+			b:    lines(12, map[int]string{1: "", 12: "line L"}),
+			want: "--- a\n+++ b\n@@ -1,4 +1,3 @@\n-line a\n line b\n line c\n line d\n@@ -9,4 +8,4 @@\n line i\n line j\n line k\n-line l\n+line L\n",
+		},
+		{
+			name: "nearby changes share a hunk",
+			a:    lines(8, nil),
+			b:    lines(8, map[int]string{2: "line B", 6: "line F"}),
+			want: "--- a\n+++ b\n@@ -1,8 +1,8 @@\n line a\n-line b\n+line B\n line c\n line d\n line e\n-line f\n+line F\n line g\n line h\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Unified("a", "b", tt.a, tt.b); got != tt.want {
+				t.Errorf("Unified() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
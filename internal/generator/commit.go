@@ -0,0 +1,164 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// commitFiles writes files (and the empty dirs) below root so that either
+// every file is written or root is left as it was.
+//
+// A root that doesn't exist yet is built in a temporary sibling directory
+// and renamed into place. Otherwise the files are staged inside root and
+// renamed over their targets one by one; replaced files are kept aside
+// until every rename succeeded and are put back if one fails.
+func commitFiles(root string, files []File, dirs ...string) error {
+	if _, err := os.Stat(root); errors.Is(err, os.ErrNotExist) {
+		return createTree(root, files, dirs)
+	}
+	return replaceFiles(root, files, dirs)
+}
+
+func createTree(root string, files []File, dirs []string) error {
+	parent := filepath.Dir(root)
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	stage, err := os.MkdirTemp(parent, "."+filepath.Base(root)+"-*")
+	if err != nil {
+		return fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	if err := writeTree(stage, files, dirs); err != nil {
+		os.RemoveAll(stage)
+		return err
+	}
+	if err := os.Chmod(stage, 0755); err != nil {
+		os.RemoveAll(stage)
+		return err
+	}
+	if err := os.Rename(stage, root); err != nil {
+		os.RemoveAll(stage)
+		return fmt.Errorf("failed to move project into place: %w", err)
+	}
+
+	return nil
+}
+
+// writeTree writes files and creates dirs below dir.
+func writeTree(dir string, files []File, dirs []string) error {
+	for _, d := range dirs {
+		if err := os.MkdirAll(filepath.Join(dir, d), 0755); err != nil {
+			return fmt.Errorf("failed to create directory %s: %w", d, err)
+		}
+	}
+
+	for _, file := range files {
+		filePath := filepath.Join(dir, file.Path)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			return fmt.Errorf("failed to generate %s: %w", file.Path, err)
+		}
+		if err := os.WriteFile(filePath, file.Content, 0644); err != nil {
+			return fmt.Errorf("failed to generate %s: %w", file.Path, err)
+		}
+	}
+
+	return nil
+}
+
+func replaceFiles(root string, files []File, dirs []string) error {
+	stage, err := os.MkdirTemp(root, ".ginboot-stage-*")
+	if err != nil {
+		return fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer os.RemoveAll(stage)
+
+	staged := filepath.Join(stage, "new")
+	if err := writeTree(staged, files, nil); err != nil {
+		return err
+	}
+	backups := filepath.Join(stage, "old")
+	if err := os.Mkdir(backups, 0755); err != nil {
+		return err
+	}
+
+	// Everything below is undone in reverse order if a step fails.
+	type replacement struct {
+		target string
+		backup string // Empty when the target didn't exist
+	}
+	var created []string
+	var replaced []replacement
+	rollback := func() {
+		for i := len(replaced) - 1; i >= 0; i-- {
+			os.Remove(replaced[i].target)
+			if replaced[i].backup != "" {
+				os.Rename(replaced[i].backup, replaced[i].target)
+			}
+		}
+		for i := len(created) - 1; i >= 0; i-- {
+			os.Remove(created[i])
+		}
+	}
+
+	for _, d := range dirs {
+		if err := mkdirAll(filepath.Join(root, d), &created); err != nil {
+			rollback()
+			return fmt.Errorf("failed to create directory %s: %w", d, err)
+		}
+	}
+
+	for i, file := range files {
+		target := filepath.Join(root, file.Path)
+		source := filepath.Join(staged, file.Path)
+		if err := mkdirAll(filepath.Dir(target), &created); err != nil {
+			rollback()
+			return fmt.Errorf("failed to generate %s: %w", file.Path, err)
+		}
+
+		r := replacement{target: target}
+		if info, err := os.Lstat(target); err == nil {
+			if info.IsDir() {
+				rollback()
+				return fmt.Errorf("failed to generate %s: it is a directory", file.Path)
+			}
+			os.Chmod(source, info.Mode().Perm())
+			r.backup = filepath.Join(backups, strconv.Itoa(i))
+			if err := os.Rename(target, r.backup); err != nil {
+				rollback()
+				return fmt.Errorf("failed to generate %s: %w", file.Path, err)
+			}
+		}
+		replaced = append(replaced, r)
+
+		if err := os.Rename(source, target); err != nil {
+			rollback()
+			return fmt.Errorf("failed to generate %s: %w", file.Path, err)
+		}
+	}
+
+	return nil
+}
+
+// mkdirAll is os.MkdirAll that records the directories it created.
+func mkdirAll(dir string, created *[]string) error {
+	if info, err := os.Stat(dir); err == nil {
+		if !info.IsDir() {
+			return fmt.Errorf("%s is not a directory", dir)
+		}
+		return nil
+	}
+
+	if err := mkdirAll(filepath.Dir(dir), created); err != nil {
+		return err
+	}
+	if err := os.Mkdir(dir, 0755); err != nil {
+		return err
+	}
+	*created = append(*created, dir)
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReplaceFilesRollback(t *testing.T) {
+	root := t.TempDir()
+	for path, content := range map[string]string{
+		"main.go":     "package main // original\n",
+		"go.mod":      "module example.com/demo\n",
+		"internal/di": "",
+	} {
+		path = filepath.Join(root, path)
+		if content == "" {
+			if err := os.MkdirAll(path, 0755); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The last file collides with a directory, so the replacement fails
+	// after the files before it were moved into place
+	files := []File{
+		{Path: "main.go", Content: []byte("package main // updated\n")},
+		{Path: "internal/service/order_service.go", Content: []byte("package service\n")},
+		{Path: "go.mod", Content: []byte("module example.com/updated\n")},
+		{Path: "internal/di", Content: []byte("package di\n")},
+	}
+	if err := commitFiles(root, files, "internal/model"); err == nil {
+		t.Fatal("commitFiles() replaced a directory with a file")
+	}
+
+	for path, want := range map[string]string{
+		"main.go": "package main // original\n",
+		"go.mod":  "module example.com/demo\n",
+	} {
+		got, err := os.ReadFile(filepath.Join(root, path))
+		if err != nil || string(got) != want {
+			t.Errorf("%s = %q, %v, want the original %q", path, got, err, want)
+		}
+	}
+	if info, err := os.Stat(filepath.Join(root, "main.go")); err != nil {
+		t.Error(err)
+	} else if info.Mode().Perm() != 0600 {
+		t.Errorf("main.go mode = %v, want the original 0600", info.Mode().Perm())
+	}
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	if len(names) != 3 {
+		t.Errorf("project holds %v after the rollback, want go.mod, internal and main.go", names)
+	}
+	for _, dir := range []string{"internal/service", "internal/model"} {
+		if _, err := os.Stat(filepath.Join(root, dir)); !os.IsNotExist(err) {
+			t.Errorf("%s was left behind: %v", dir, err)
+		}
+	}
+}
+
+func TestCreateTreeRollback(t *testing.T) {
+	parent := t.TempDir()
+	root := filepath.Join(parent, "demo")
+
+	// internal/di is written as a file before a file below it is needed
+	files := []File{
+		{Path: "main.go", Content: []byte("package main\n")},
+		{Path: "internal/di", Content: []byte("package di\n")},
+		{Path: "internal/di/container.go", Content: []byte("package di\n")},
+	}
+	if err := commitFiles(root, files); err == nil {
+		t.Fatal("commitFiles() wrote a file below another file")
+	}
+
+	entries, err := os.ReadDir(parent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		t.Errorf("%s was left behind", entry.Name())
+	}
+}
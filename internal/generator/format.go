@@ -0,0 +1,193 @@
+package generator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// formatGo gofmts a rendered Go file. Like goimports, it first drops unused
+// imports and regroups the rest into standard library and other imports, so
+// conditional imports in templates don't leave gaps or stray lines behind.
+// Imports below module are grouped with the other non-standard imports.
+func formatGo(filename, module string, src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, sourceError(src, err)
+	}
+
+	src = fixImports(fset, file, src, module)
+
+	formatted, err := format.Source(src)
+	if err != nil {
+		return nil, sourceError(src, err)
+	}
+	return formatted, nil
+}
+
+// sourceError adds the offending source line to a parse error.
+func sourceError(src []byte, err error) error {
+	var list scanner.ErrorList
+	if !errors.As(err, &list) || len(list) == 0 {
+		return fmt.Errorf("invalid Go source: %w", err)
+	}
+
+	pos := list[0].Pos
+	lines := strings.Split(string(src), "\n")
+	if pos.Line < 1 || pos.Line > len(lines) {
+		return fmt.Errorf("invalid Go source: %w", err)
+	}
+	return fmt.Errorf("invalid Go source: %w\n\t%4d | %s", err, pos.Line, strings.TrimRight(lines[pos.Line-1], " \t"))
+}
+
+// fixImports rewrites the import declarations of file as a single sorted
+// block without unused imports. The source is returned unchanged when the
+// imports carry comments or are interleaved with other declarations.
+func fixImports(fset *token.FileSet, file *ast.File, src []byte, module string) []byte {
+	var decls []*ast.GenDecl
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			break
+		}
+		decls = append(decls, gen)
+	}
+	if len(decls) == 0 || len(decls) != countImportDecls(file) {
+		return src
+	}
+	for _, decl := range decls {
+		if decl.Doc != nil {
+			return src
+		}
+		for _, spec := range decl.Specs {
+			imp := spec.(*ast.ImportSpec)
+			if imp.Doc != nil || imp.Comment != nil {
+				return src
+			}
+		}
+	}
+
+	used := usedPackages(file)
+	var std, other []string
+	for _, imp := range file.Imports {
+		importPath, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			return src
+		}
+		name := importName(imp, importPath)
+		if name != "" && name != "_" && name != "." && !used[name] {
+			continue
+		}
+
+		line := imp.Path.Value
+		if imp.Name != nil {
+			line = imp.Name.Name + " " + line
+		}
+		if isStdlib(importPath) && importPath != module && !strings.HasPrefix(importPath, module+"/") {
+			std = append(std, line)
+		} else {
+			other = append(other, line)
+		}
+	}
+	sort.Slice(std, func(i, j int) bool { return importPathOf(std[i]) < importPathOf(std[j]) })
+	sort.Slice(other, func(i, j int) bool { return importPathOf(other[i]) < importPathOf(other[j]) })
+
+	var block bytes.Buffer
+	switch {
+	case len(std)+len(other) == 1:
+		block.WriteString("import " + append(std, other...)[0])
+	case len(std)+len(other) > 1:
+		block.WriteString("import (\n")
+		for _, line := range std {
+			block.WriteString("\t" + line + "\n")
+		}
+		if len(std) > 0 && len(other) > 0 {
+			block.WriteString("\n")
+		}
+		for _, line := range other {
+			block.WriteString("\t" + line + "\n")
+		}
+		block.WriteString(")")
+	}
+
+	start := fset.Position(decls[0].Pos()).Offset
+	end := fset.Position(decls[len(decls)-1].End()).Offset
+	for _, c := range file.Comments {
+		if offset := fset.Position(c.Pos()).Offset; offset > start && offset < end {
+			return src
+		}
+	}
+
+	out := make([]byte, 0, len(src))
+	out = append(out, src[:start]...)
+	out = append(out, block.Bytes()...)
+	return append(out, src[end:]...)
+}
+
+func countImportDecls(file *ast.File) int {
+	n := 0
+	for _, decl := range file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			n++
+		}
+	}
+	return n
+}
+
+// usedPackages returns the identifiers used as package qualifiers.
+func usedPackages(file *ast.File) map[string]bool {
+	used := map[string]bool{}
+	ast.Inspect(file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok {
+				used[ident.Name] = true
+			}
+		}
+		return true
+	})
+	return used
+}
+
+// importName returns the name an import is referred to by, or "" when it
+// can't be told from the path alone; such imports are always kept.
+func importName(imp *ast.ImportSpec, importPath string) string {
+	if imp.Name != nil {
+		return imp.Name.Name
+	}
+
+	name := path.Base(importPath)
+	if major, ok := strings.CutPrefix(name, "v"); ok && importPath != name {
+		if _, err := strconv.Atoi(major); err == nil {
+			name = path.Base(path.Dir(importPath))
+		}
+	}
+	if i := strings.Index(name, ".v"); i > 0 && strings.HasPrefix(importPath, "gopkg.in/") {
+		name = name[:i]
+	}
+	if strings.ContainsAny(name, ".-") {
+		return ""
+	}
+	return name
+}
+
+func isStdlib(importPath string) bool {
+	first, _, _ := strings.Cut(importPath, "/")
+	return !strings.Contains(first, ".")
+}
+
+func importPathOf(line string) string {
+	if i := strings.IndexByte(line, '"'); i >= 0 {
+		return line[i:]
+	}
+	return line
+}
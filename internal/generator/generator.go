@@ -1,52 +1,55 @@
 package generator
 
 import (
-	"encoding/json"
+	"bytes"
+	"errors"
 	"fmt"
-	"io/ioutil"
-	"net/http"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"text/template"
+
+	"github.com/klass-lk/ginboot-cli/internal/manifest"
 )
 
-type GitHubRelease struct {
-	TagName string `json:"tag_name"`
-}
+type ProjectGenerator struct {
+	ProjectPath    string
+	ProjectName    string
+	ModuleName     string
+	GoVersion      string
+	DatabaseType   string
+	StorageType    string
+	DeployType     string
+	HasTelemetry   bool
+	GinbootVersion string
 
-func getLatestGinbootVersion() string {
-	resp, err := http.Get("https://api.github.com/repos/Klass-lk/GinBoot/releases/latest")
-	if err != nil {
-		return "v1.14.2"
-	}
-	defer resp.Body.Close()
+	// Arch and Runtime are the Lambda architecture and runtime; DefaultArch
+	// and DefaultRuntime are used when empty.
+	Arch    string
+	Runtime string
 
-	if resp.StatusCode != http.StatusOK {
-		return "v1.14.2"
-	}
+	// Templates is the template tree to render; DefaultTemplates, with Pack
+	// layered on top, is used when nil.
+	Templates fs.FS
 
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return "v1.14.2"
-	}
+	// Pack is an optional template pack and Values the answers to its
+	// prompts.
+	Pack   *TemplatePack
+	Values map[string]string
 
-	var release GitHubRelease
-	if err := json.Unmarshal(body, &release); err != nil {
-		return "v1.14.2"
-	}
+	// Output receives the generated files; a DirWriter for ProjectPath is
+	// used when nil.
+	Output Writer
 
-	return release.TagName
-}
+	// Force lets Generate overwrite existing files that differ from the
+	// rendered ones.
+	Force bool
 
-type ProjectGenerator struct {
-	ProjectPath  string
-	ProjectName  string
-	ModuleName   string
-	GoVersion    string
-	DatabaseType string
-	StorageType  string
-	DeployType   string
-	HasTelemetry bool
+	// Progress, if set, is called by ProjectFiles after each file it
+	// renders, with the number of files rendered so far and in total.
+	Progress func(path string, done, total int)
 }
 
 func NewProjectGenerator(projectPath, projectName, moduleName, goVersion, databaseType, storageType, deployType string, hasTelemetry bool) *ProjectGenerator {
@@ -62,138 +65,359 @@ func NewProjectGenerator(projectPath, projectName, moduleName, goVersion, databa
 	}
 }
 
+// NewProjectGeneratorFromManifest returns a generator for an existing project
+// described by its ginboot.yaml manifest.
+func NewProjectGeneratorFromManifest(projectPath string, m *manifest.Manifest) *ProjectGenerator {
+	g := NewProjectGenerator(projectPath, m.ProjectName, m.ModuleName, m.GoVersion, m.Database, m.Storage, m.Deploy, m.Telemetry)
+	g.GinbootVersion = m.GinbootVersion
+	g.Arch = m.Arch
+	g.Runtime = m.Runtime
+	if m.Template != nil {
+		g.Values = m.Template.Values
+	}
+	return g
+}
+
+// Manifest returns the ginboot.yaml manifest describing the generated project.
+func (g *ProjectGenerator) Manifest() *manifest.Manifest {
+	var tmpl *manifest.Template
+	if g.Pack != nil {
+		tmpl = &manifest.Template{Source: g.packSource(), Commit: g.Pack.Commit, Values: g.Values}
+	}
+
+	m := &manifest.Manifest{
+		ProjectName:    g.ProjectName,
+		ModuleName:     g.ModuleName,
+		GoVersion:      g.GoVersion,
+		GinbootVersion: g.GinbootVersion,
+		Database:       g.DatabaseType,
+		Storage:        g.StorageType,
+		Deploy:         g.DeployType,
+		Telemetry:      g.HasTelemetry,
+		Template:       tmpl,
+	}
+	if g.hasLambda() {
+		m.Arch = g.arch()
+		m.Runtime = g.runtime()
+	}
+	return m
+}
+
+// packSource returns the pack source as recorded in the manifest: local
+// directories are made relative to the project so the project can move
+// together with its pack.
+func (g *ProjectGenerator) packSource() string {
+	source := g.Pack.Source
+	if isGitSource(source) {
+		return source
+	}
+	abs, err := filepath.Abs(source)
+	if err != nil {
+		return source
+	}
+	projectPath, err := filepath.Abs(g.ProjectPath)
+	if err != nil {
+		return abs
+	}
+	if rel, err := filepath.Rel(projectPath, abs); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return abs
+}
+
+// File is a rendered project file, relative to the project root.
+type File struct {
+	Path    string
+	Content []byte
+}
+
+// Generate writes a new project. Existing files that would change are only
+// overwritten when Force is set.
 func (g *ProjectGenerator) Generate() error {
-	// Create directory structure
-	dirs := []string{
-		"internal/controller",
-		"internal/repository",
-		"internal/model",
-		"internal/service",
-		"internal/di",
-	}
-
-	for _, dir := range dirs {
-		if err := os.MkdirAll(filepath.Join(g.ProjectPath, dir), 0755); err != nil {
-			return fmt.Errorf("failed to create directory %s: %w", dir, err)
-		}
+	files, err := g.ProjectFiles()
+	if err != nil {
+		return err
 	}
 
-	// Select templates based on database choice
-	var mainTmpl, goModTmpl, dockerComposeTmpl, userModelTmpl, userRepoTmpl string
-
-	switch g.DatabaseType {
-	case "mongodb":
-		mainTmpl = mainMongoTemplate
-		goModTmpl = goModMongoTemplate
-		dockerComposeTmpl = dockerComposeMongoTemplate
-		userModelTmpl = userModelMongoTemplate
-		userRepoTmpl = userRepositoryMongoTemplate
-	case "postgres":
-		mainTmpl = mainPostgresTemplate
-		goModTmpl = goModPostgresTemplate
-		dockerComposeTmpl = dockerComposePostgresTemplate
-		userModelTmpl = userModelPostgresTemplate
-		userRepoTmpl = userRepositoryPostgresTemplate
-	case "mysql":
-		mainTmpl = mainMysqlTemplate
-		goModTmpl = goModMysqlTemplate
-		dockerComposeTmpl = dockerComposeMysqlTemplate
-		userModelTmpl = userModelMysqlTemplate
-		userRepoTmpl = userRepositoryMysqlTemplate
-	case "dynamodb":
-		mainTmpl = mainDynamodbTemplate
-		goModTmpl = goModDynamodbTemplate
-		dockerComposeTmpl = dockerComposeDynamodbTemplate
-		userModelTmpl = userModelDynamodbTemplate
-		userRepoTmpl = userRepositoryDynamodbTemplate
-	default: // "none"
-		mainTmpl = mainTemplate
-		goModTmpl = goModNoneTemplate
-		dockerComposeTmpl = dockerComposeNoneTemplate
-		userModelTmpl = userModelNoneTemplate
-		userRepoTmpl = "" // Removed for inmemory pattern
-	}
-
-	// Generate files
-	files := map[string]string{
-		"main.go": mainTmpl,
-		"go.mod":  goModTmpl,
-	}
-
-	if g.DeployType == "lambda" {
-		files["Makefile"] = makefileTemplate
-		files["template.yaml"] = templateYamlTemplate
-		files["Dockerfile"] = dockerfileTemplate
-	}
-
-	if dockerComposeTmpl != "" {
-		files["docker-compose.yml"] = dockerComposeTmpl
-	}
-
-	for filename, tmpl := range files {
-		if err := g.generateFile(filename, tmpl); err != nil {
-			return fmt.Errorf("failed to generate %s: %w", filename, err)
+	if !g.Force {
+		overwrites, err := g.Overwrites(files)
+		if err != nil {
+			return err
+		}
+		if len(overwrites) > 0 {
+			return fmt.Errorf("%d existing file(s) would be overwritten, starting with %s", len(overwrites), overwrites[0].File.Path)
 		}
 	}
 
-	// Generate internal package files
-	internalFiles := map[string]string{
-		"internal/controller/user_controller.go": userControllerTemplate,
-		"internal/model/user.go":                 userModelTmpl,
-		"internal/service/user_service.go":       userServiceTemplate,
-		"internal/di/container.go":               diContainerTemplate,
+	return g.Write(files)
+}
+
+// ProjectFiles renders the project, including its ginboot.yaml manifest,
+// without writing anything. GinbootVersion must be set, e.g. from
+// ResolveGinbootVersion.
+func (g *ProjectGenerator) ProjectFiles() ([]File, error) {
+	if g.GinbootVersion == "" {
+		return nil, errors.New("no Ginboot version to generate the project for")
+	}
+
+	templates, err := g.projectTemplates()
+	if err != nil {
+		return nil, err
+	}
+	done, total := 0, len(templates)+1
+	rendered := func(path string) {
+		done++
+		if g.Progress != nil {
+			g.Progress(path, done, total)
+		}
 	}
-	
-	if userRepoTmpl != "" {
-		internalFiles["internal/repository/user_repository.go"] = userRepoTmpl
+	files, err := g.renderFiles(templates, g.templateData(), rendered)
+	if err != nil {
+		return nil, err
 	}
 
-	for filename, tmpl := range internalFiles {
-		if err := g.generateFile(filename, tmpl); err != nil {
-			return fmt.Errorf("failed to generate %s: %w", filename, err)
+	m := g.Manifest()
+	m.Files = Checksums(files)
+	data, err := m.Marshal()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate %s: %w", manifest.FileName, err)
+	}
+	rendered(manifest.FileName)
+
+	return append(files, File{Path: manifest.FileName, Content: data}), nil
+}
+
+// Overwrites returns the files that already exist below the project path
+// with different content. Files with identical content are not reported.
+func (g *ProjectGenerator) Overwrites(files []File) ([]Change, error) {
+	var changes []Change
+	for _, file := range files {
+		existing, err := os.ReadFile(filepath.Join(g.ProjectPath, file.Path))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
 		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file.Path, err)
+		}
+		if !bytes.Equal(existing, file.Content) {
+			changes = append(changes, Change{File: file, Action: ActionUpdate, Existing: existing})
+		}
+	}
+	return changes, nil
+}
+
+// projectDirs are created even when no file is generated in them.
+var projectDirs = []string{
+	"internal/controller",
+	"internal/repository",
+	"internal/model",
+	"internal/service",
+	"internal/di",
+}
+
+// Write creates the project directory structure and writes the files to
+// the generator's output.
+func (g *ProjectGenerator) Write(files []File) error {
+	return g.output().Write(files, projectDirs)
+}
+
+func (g *ProjectGenerator) output() Writer {
+	if g.Output == nil {
+		return DirWriter{Root: g.ProjectPath}
+	}
+	return g.Output
+}
+
+// projectFileConditions limits project files to the options that need them.
+// Files without a condition are always generated.
+var projectFileConditions = map[string]func(g *ProjectGenerator) bool{
+	"Makefile":                               (*ProjectGenerator).hasLambda,
+	"template.yaml":                          (*ProjectGenerator).hasLambda,
+	"Dockerfile":                             (*ProjectGenerator).hasLambda,
+	"internal/repository/user_repository.go": (*ProjectGenerator).hasRepository,
+}
+
+func (g *ProjectGenerator) hasLambda() bool {
+	return g.DeployType == "lambda"
+}
+
+// Lambda architecture and runtime of projects that don't choose one.
+const (
+	DefaultArch    = "x86_64"
+	DefaultRuntime = "provided.al2"
+)
+
+func (g *ProjectGenerator) arch() string {
+	if g.Arch == "" {
+		return DefaultArch
+	}
+	return g.Arch
+}
+
+func (g *ProjectGenerator) runtime() string {
+	if g.Runtime == "" {
+		return DefaultRuntime
 	}
+	return g.Runtime
+}
+
+// GoArch returns the GOARCH that builds for a Lambda architecture.
+func GoArch(arch string) string {
+	if arch == "arm64" {
+		return "arm64"
+	}
+	return "amd64"
+}
 
-	return nil
+// hasRepository reports whether entities get a generated repository type;
+// the in-memory database uses ginboot's repository directly.
+func (g *ProjectGenerator) hasRepository() bool {
+	return g.DatabaseType != "none"
 }
 
-func (g *ProjectGenerator) generateFile(filename, tmplContent string) error {
-	filePath := filepath.Join(g.ProjectPath, filename)
+// Render executes every project template in memory and returns the files
+// sorted by path.
+func (g *ProjectGenerator) Render() ([]File, error) {
+	files, err := g.projectTemplates()
+	if err != nil {
+		return nil, err
+	}
+	return g.renderFiles(files, g.templateData(), nil)
+}
 
-	tmpl, err := template.New(filename).Parse(tmplContent)
+// projectTemplates returns the templates of the project's files keyed by
+// output path.
+func (g *ProjectGenerator) projectTemplates() (map[string]string, error) {
+	templates, err := projectTemplates(g.templates())
 	if err != nil {
-		return fmt.Errorf("failed to parse template: %w", err)
+		return nil, err
+	}
+
+	files := map[string]string{}
+	for path, name := range templates {
+		if cond, ok := projectFileConditions[path]; ok && !cond(g) {
+			continue
+		}
+		files[path] = name
+	}
+	if g.Pack != nil {
+		for _, file := range g.Pack.Files {
+			if file.When.matches(g) {
+				files[file.Path] = file.Template
+			}
+		}
+	}
+
+	return files, nil
+}
+
+func (g *ProjectGenerator) templates() fs.FS {
+	if g.Templates == nil {
+		g.Templates = DefaultTemplates(g.ProjectPath)
+		if g.Pack != nil {
+			g.Templates = layeredFS{g.Pack.FS, g.Templates}
+		}
 	}
+	return g.Templates
+}
+
+// Checksums returns the manifest checksums of the files keyed by path.
+func Checksums(files []File) map[string]string {
+	sums := make(map[string]string, len(files))
+	for _, file := range files {
+		sums[file.Path] = manifest.Checksum(file.Content)
+	}
+	return sums
+}
 
-	f, err := os.Create(filePath)
+// renderFiles executes the named templates, keyed by output path. Go files
+// are formatted and must be valid Go. rendered, if not nil, is called after
+// each file.
+func (g *ProjectGenerator) renderFiles(files map[string]string, data templateData, rendered func(path string)) ([]File, error) {
+	base, err := parsePartials(g.templates())
 	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
-	}
-	defer f.Close()
-
-	data := struct {
-		ProjectName    string
-		ModuleName     string
-		GoVersion      string
-		DatabaseType   string
-		GinbootVersion string
-		HasS3          bool
-		HasLambda      bool
-		HasTelemetry   bool
-	}{
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	out := make([]File, 0, len(paths))
+	for _, path := range paths {
+		content, err := g.renderFile(base, files[path], data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate %s: %w", path, err)
+		}
+		if strings.HasSuffix(path, ".go") {
+			content, err = formatGo(path, g.ModuleName, content)
+			if err != nil {
+				return nil, fmt.Errorf("failed to generate %s: %w", path, err)
+			}
+		}
+		out = append(out, File{Path: path, Content: content})
+		if rendered != nil {
+			rendered(path)
+		}
+	}
+
+	return out, nil
+}
+
+// templateData is the data every template is executed against.
+type templateData struct {
+	ProjectName    string
+	ModuleName     string
+	GoVersion      string
+	DatabaseType   string
+	GinbootVersion string
+	HasS3          bool
+	HasLambda      bool
+	HasTelemetry   bool
+	Arch           string // Lambda architecture: x86_64 or arm64
+	GoArch         string // GOARCH for Arch
+	Runtime        string // Lambda runtime, e.g. provided.al2023
+	Resource       *Resource
+	Values         map[string]string // Answers to template pack prompts
+}
+
+func (g *ProjectGenerator) templateData() templateData {
+	return templateData{
 		ProjectName:    g.ProjectName,
 		ModuleName:     g.ModuleName,
 		GoVersion:      g.GoVersion,
 		DatabaseType:   g.DatabaseType,
-		GinbootVersion: getLatestGinbootVersion(),
+		GinbootVersion: g.GinbootVersion,
 		HasS3:          g.StorageType == "s3",
 		HasLambda:      g.DeployType == "lambda",
 		HasTelemetry:   g.HasTelemetry,
+		Arch:           g.arch(),
+		GoArch:         GoArch(g.arch()),
+		Runtime:        g.runtime(),
+		Values:         g.Values,
+	}
+}
+
+func (g *ProjectGenerator) renderFile(base *template.Template, name string, data templateData) ([]byte, error) {
+	content, err := fs.ReadFile(g.templates(), name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template %s: %w", name, err)
+	}
+
+	tmpl, err := base.Clone()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tmpl.New(name).Parse(string(content)); err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
 	}
 
-	if err := tmpl.Execute(f, data); err != nil {
-		return fmt.Errorf("failed to execute template: %w", err)
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, name, data); err != nil {
+		return nil, fmt.Errorf("failed to execute template: %w", err)
 	}
 
-	return nil
+	return buf.Bytes(), nil
 }
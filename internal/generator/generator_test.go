@@ -0,0 +1,171 @@
+package generator
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/klass-lk/ginboot-cli/internal/diff"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/golden")
+
+// TestGolden renders every combination of project options, plus a generated
+// resource, and compares the output with testdata/golden. Run
+// `go test ./internal/generator -update` after changing a template.
+func TestGolden(t *testing.T) {
+	builtin, err := fs.Sub(embeddedTemplates, "templates")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, db := range []string{"none", "mongodb", "postgres", "mysql", "dynamodb"} {
+		for _, storage := range []string{"none", "s3"} {
+			for _, deploy := range []string{"http", "lambda"} {
+				for _, telemetry := range []bool{false, true} {
+					name := fmt.Sprintf("%s-%s-%s", db, storage, deploy)
+					if telemetry {
+						name += "-telemetry"
+					}
+
+					t.Run(name, func(t *testing.T) {
+						g := NewProjectGenerator(t.TempDir(), "demo", "example.com/demo", "1.21", db, storage, deploy, telemetry)
+						g.GinbootVersion = "v1.14.2"
+						g.Templates = builtin
+
+						compareGolden(t, filepath.Join("testdata", "golden", name), renderGoldenProject(t, g))
+					})
+				}
+			}
+		}
+	}
+
+	t.Run("postgres-none-lambda-arm64", func(t *testing.T) {
+		g := NewProjectGenerator(t.TempDir(), "demo", "example.com/demo", "1.21", "postgres", "none", "lambda", false)
+		g.GinbootVersion = "v1.14.2"
+		g.Templates = builtin
+		g.Arch = "arm64"
+		g.Runtime = "provided.al2023"
+
+		compareGolden(t, filepath.Join("testdata", "golden", "postgres-none-lambda-arm64"), renderGoldenProject(t, g))
+	})
+}
+
+// renderGoldenProject renders the project and an Order resource registered
+// in its container, and checks that the Go files parse.
+func renderGoldenProject(t *testing.T, g *ProjectGenerator) []File {
+	t.Helper()
+
+	files, err := g.ProjectFiles()
+	if err != nil {
+		t.Fatalf("ProjectFiles() error = %v", err)
+	}
+
+	var container []byte
+	for _, file := range files {
+		if file.Path == containerFile {
+			container = file.Content
+		}
+	}
+
+	r, err := NewResource("Order", "total:float64,status:string,placedAt:time.Time")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resource, err := g.RenderResource(r, container)
+	if err != nil {
+		t.Fatalf("RenderResource() error = %v", err)
+	}
+
+	byPath := map[string]File{}
+	for _, file := range append(files, resource...) {
+		byPath[file.Path] = file
+	}
+	paths := make([]string, 0, len(byPath))
+	for path := range byPath {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	all := make([]File, 0, len(paths))
+	for _, path := range paths {
+		file := byPath[path]
+		if strings.HasSuffix(file.Path, ".go") {
+			parseGo(t, file)
+		}
+		all = append(all, file)
+	}
+	return all
+}
+
+func parseGo(t *testing.T, file File) {
+	t.Helper()
+	if _, err := parser.ParseFile(token.NewFileSet(), file.Path, file.Content, parser.AllErrors); err != nil {
+		t.Errorf("%s is not valid Go: %v", file.Path, err)
+	}
+}
+
+// compareGolden compares files with dir, where each file is stored as
+// <path>.golden, or rewrites dir with -update.
+func compareGolden(t *testing.T, dir string, files []File) {
+	t.Helper()
+
+	if *update {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Fatal(err)
+		}
+		for _, file := range files {
+			path := filepath.Join(dir, filepath.FromSlash(file.Path)+".golden")
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, file.Content, 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return
+	}
+
+	want := map[string][]byte{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		want[strings.TrimSuffix(filepath.ToSlash(rel), ".golden")] = content
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to read golden files (run with -update to create them): %v", err)
+	}
+
+	for _, file := range files {
+		expected, ok := want[file.Path]
+		if !ok {
+			t.Errorf("%s: unexpected file", file.Path)
+			continue
+		}
+		delete(want, file.Path)
+		if !bytes.Equal(expected, file.Content) {
+			t.Errorf("%s differs from golden file:\n%s", file.Path, diff.Unified("golden/"+file.Path, file.Path, expected, file.Content))
+		}
+	}
+	for path := range want {
+		t.Errorf("%s: missing file", path)
+	}
+}
@@ -0,0 +1,303 @@
+package generator
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/klass-lk/ginboot-cli/internal/runner"
+	"gopkg.in/yaml.v2"
+)
+
+// tools runs git for template packs; tests replace it with a
+// runner.Recorder.
+var tools runner.Runner = runner.Exec{}
+
+// PackManifestFile is the manifest at the root of a template pack.
+const PackManifestFile = "ginboot-template.yaml"
+
+// TemplatePack is a set of organisation templates layered over the built-in
+// ones. Besides overriding partials/, project/ and resource/ templates by
+// path, a pack declares extra files and prompts in its manifest:
+//
+//	name: acme
+//	prompts:
+//	  - name: team
+//	    message: Owning team
+//	    default: platform
+//	files:
+//	  - path: internal/middleware/auth.go
+//	    template: files/auth.go.tmpl
+//	    when:
+//	      deploy: [lambda]
+type TemplatePack struct {
+	Name        string       `yaml:"name"`
+	Description string       `yaml:"description"`
+	Prompts     []PackPrompt `yaml:"prompts"`
+	Files       []PackFile   `yaml:"files"`
+
+	// Source is where the pack was loaded from, as given by the user.
+	Source string `yaml:"-"`
+	// Commit is the commit a git pack was checked out at.
+	Commit string `yaml:"-"`
+	// FS is the pack's file tree.
+	FS fs.FS `yaml:"-"`
+}
+
+// PackPrompt is an extra question asked by `ginboot new`. The answer is
+// available to templates as {{ .Values.<name> }}.
+type PackPrompt struct {
+	Name    string `yaml:"name"`
+	Message string `yaml:"message"`
+	Default string `yaml:"default"`
+}
+
+// PackFile maps a pack template to an output path in the project.
+type PackFile struct {
+	Path     string        `yaml:"path"`
+	Template string        `yaml:"template"`
+	When     PackCondition `yaml:"when"`
+}
+
+// PackCondition limits a pack file to projects with matching options. An
+// empty list matches any value.
+type PackCondition struct {
+	Database []string `yaml:"database"`
+	Deploy   []string `yaml:"deploy"`
+	Storage  []string `yaml:"storage"`
+}
+
+func (c PackCondition) matches(g *ProjectGenerator) bool {
+	return matchesAny(c.Database, g.DatabaseType) &&
+		matchesAny(c.Deploy, g.DeployType) &&
+		matchesAny(c.Storage, g.StorageType)
+}
+
+func matchesAny(values []string, value string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadTemplatePack loads a template pack from a local directory or a git
+// repository. Git sources (https://, ssh://, git@, file:// or *.git) are
+// shallow-cloned into the user cache dir; an optional #ref selects a branch
+// or tag, and the commit it resolved to is recorded in the pack's Commit.
+// When offline, remote git sources are read from the last clone; local
+// repositories (file:// or a path ending in .git) are still cloned.
+func LoadTemplatePack(ctx context.Context, source string, offline bool) (*TemplatePack, error) {
+	return LoadPinnedTemplatePack(ctx, source, "", offline)
+}
+
+// LoadPinnedTemplatePack loads a template pack like LoadTemplatePack, but
+// at the given commit of a git source, so a project keeps rendering the
+// templates it was generated from. Checkouts of a commit are cached, so
+// only the first load of a commit touches the network. An empty commit
+// loads the latest templates of the source.
+func LoadPinnedTemplatePack(ctx context.Context, source, commit string, offline bool) (*TemplatePack, error) {
+	dir := source
+	if isGitSource(source) {
+		var err error
+		dir, commit, err = fetchTemplatePack(ctx, source, commit, offline)
+		if err != nil {
+			return nil, err
+		}
+	} else if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("template pack %s is not a directory or git URL", source)
+	} else {
+		commit = ""
+	}
+
+	pack, err := ParseTemplatePack(os.DirFS(dir))
+	if err != nil {
+		return nil, fmt.Errorf("invalid template pack %s: %w", source, err)
+	}
+	pack.Source = source
+	pack.Commit = commit
+
+	return pack, nil
+}
+
+// ParseTemplatePack reads and validates the manifest of the pack in fsys.
+func ParseTemplatePack(fsys fs.FS) (*TemplatePack, error) {
+	data, err := fs.ReadFile(fsys, PackManifestFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", PackManifestFile, err)
+	}
+
+	var pack TemplatePack
+	if err := yaml.UnmarshalStrict(data, &pack); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", PackManifestFile, err)
+	}
+	pack.FS = fsys
+
+	prompts := map[string]bool{}
+	for _, prompt := range pack.Prompts {
+		if prompt.Name == "" {
+			return nil, fmt.Errorf("prompt without a name")
+		}
+		if prompts[prompt.Name] {
+			return nil, fmt.Errorf("duplicate prompt %q", prompt.Name)
+		}
+		prompts[prompt.Name] = true
+	}
+
+	for _, file := range pack.Files {
+		if file.Path == "" || file.Template == "" {
+			return nil, fmt.Errorf("files entries need both a path and a template")
+		}
+		if !fs.ValidPath(file.Path) || path.IsAbs(file.Path) {
+			return nil, fmt.Errorf("file path %q must be relative to the project root", file.Path)
+		}
+		if _, err := fs.Stat(fsys, file.Template); err != nil {
+			return nil, fmt.Errorf("template %s for %s: %w", file.Template, file.Path, err)
+		}
+	}
+
+	return &pack, nil
+}
+
+// Values returns the answers to the pack's prompts: the given answers, with
+// defaults filled in for the rest.
+func (p *TemplatePack) Values(answers map[string]string) map[string]string {
+	values := make(map[string]string, len(p.Prompts))
+	for _, prompt := range p.Prompts {
+		if answer, ok := answers[prompt.Name]; ok {
+			values[prompt.Name] = answer
+		} else {
+			values[prompt.Name] = prompt.Default
+		}
+	}
+	return values
+}
+
+func isGitSource(source string) bool {
+	for _, prefix := range []string{"https://", "http://", "ssh://", "git://", "file://", "git@"} {
+		if strings.HasPrefix(source, prefix) {
+			return true
+		}
+	}
+	url, _, _ := strings.Cut(source, "#")
+	return strings.HasSuffix(url, ".git")
+}
+
+var commitPattern = regexp.MustCompile(`^[0-9a-f]{7,64}$`)
+
+// fetchTemplatePack returns a checkout of a git template pack at commit, or
+// at the latest commit of the source's ref when commit is empty, and the
+// commit checked out. Checkouts are cached by commit below the user cache
+// dir, next to a record of the latest commit of each ref.
+func fetchTemplatePack(ctx context.Context, source, commit string, offline bool) (dir, checkedOut string, err error) {
+	url, ref, _ := strings.Cut(source, "#")
+	if commit != "" && !commitPattern.MatchString(commit) {
+		return "", "", fmt.Errorf("invalid commit %q for template pack %s", commit, source)
+	}
+
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to locate cache directory: %w", err)
+	}
+	base = filepath.Join(base, "ginboot", "packs", shortHash(url))
+	if err := os.MkdirAll(base, 0755); err != nil {
+		return "", "", fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	latest := filepath.Join(base, "latest-"+shortHash(ref))
+
+	remote := strings.Contains(url, "://") && !strings.HasPrefix(url, "file://") || strings.HasPrefix(url, "git@")
+	if commit == "" && offline && remote {
+		data, err := os.ReadFile(latest)
+		if err != nil {
+			return "", "", fmt.Errorf("template pack %s has not been fetched yet; run once without --offline", source)
+		}
+		commit = strings.TrimSpace(string(data))
+	}
+
+	if commit != "" {
+		dir = filepath.Join(base, commit)
+		if _, err := os.Stat(filepath.Join(dir, PackManifestFile)); err == nil {
+			return dir, commit, nil
+		}
+		if offline && remote {
+			return "", "", fmt.Errorf("template pack %s at %s is not cached; run once without --offline", source, commit)
+		}
+	}
+
+	if _, err := tools.LookPath("git"); err != nil {
+		return "", "", errors.New("git is required for template packs from git repositories")
+	}
+	tmp, err := os.MkdirTemp(base, "fetch-*")
+	if err != nil {
+		return "", "", err
+	}
+	defer os.RemoveAll(tmp)
+
+	if commit == "" {
+		args := []string{"clone", "--quiet", "--depth", "1"}
+		if ref != "" {
+			args = append(args, "--branch", ref)
+		}
+		if _, err := git(ctx, "", append(args, url, tmp)...); err != nil {
+			return "", "", fmt.Errorf("failed to clone %s: %w", url, err)
+		}
+		head, err := git(ctx, tmp, "rev-parse", "HEAD")
+		if err != nil {
+			return "", "", err
+		}
+		commit = head
+	} else {
+		for _, args := range [][]string{
+			{"init", "--quiet"},
+			{"fetch", "--quiet", "--depth", "1", url, commit},
+			{"checkout", "--quiet", "FETCH_HEAD"},
+		} {
+			if _, err := git(ctx, tmp, args...); err != nil {
+				return "", "", fmt.Errorf("failed to fetch %s at %s: %w", url, commit, err)
+			}
+		}
+	}
+
+	dir = filepath.Join(base, commit)
+	if _, err := os.Stat(dir); errors.Is(err, fs.ErrNotExist) {
+		if err := os.Rename(tmp, dir); err != nil {
+			return "", "", fmt.Errorf("failed to cache template pack: %w", err)
+		}
+	}
+	if err := os.WriteFile(latest, []byte(commit+"\n"), 0644); err != nil {
+		return "", "", fmt.Errorf("failed to cache template pack: %w", err)
+	}
+
+	return dir, commit, nil
+}
+
+// git runs git in dir and returns its trimmed output.
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	out, err := runner.Output(ctx, tools, runner.Command{
+		Name: "git",
+		Args: args,
+		Dir:  dir,
+		Env:  []string{"GIT_TERMINAL_PROMPT=0"},
+	})
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func shortHash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:8])
+}
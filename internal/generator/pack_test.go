@@ -0,0 +1,298 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/klass-lk/ginboot-cli/internal/runner"
+)
+
+// useTempCache points the user cache dir at a temporary directory, so
+// tests don't touch the real pack cache.
+func useTempCache(t *testing.T) {
+	t.Helper()
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+}
+
+// recordGit replaces tools with a Recorder for the test. handle plays git.
+func recordGit(t *testing.T, handle func(c runner.Command) error) *runner.Recorder {
+	t.Helper()
+	rec := &runner.Recorder{Handle: handle}
+	previous := tools
+	tools = rec
+	t.Cleanup(func() { tools = previous })
+	return rec
+}
+
+func TestParseTemplatePack(t *testing.T) {
+	valid := fstest.MapFS{
+		PackManifestFile: {Data: []byte(`name: acme
+prompts:
+  - name: team
+    default: platform
+files:
+  - path: internal/middleware/auth.go
+    template: files/auth.go.tmpl
+    when:
+      deploy: [lambda]
+`)},
+		"files/auth.go.tmpl": {Data: []byte("package middleware\n")},
+	}
+	pack, err := ParseTemplatePack(valid)
+	if err != nil {
+		t.Fatalf("ParseTemplatePack() error = %v", err)
+	}
+	if pack.Name != "acme" || len(pack.Files) != 1 || pack.Files[0].When.Deploy[0] != "lambda" {
+		t.Errorf("ParseTemplatePack() = %+v", pack)
+	}
+	if values := pack.Values(map[string]string{"other": "x"}); len(values) != 1 || values["team"] != "platform" {
+		t.Errorf("Values() = %v, want the prompt's default", values)
+	}
+
+	for name, manifest := range map[string]string{
+		"unknown field":    "name: acme\nfiles_typo: []\n",
+		"unnamed prompt":   "prompts:\n  - message: Team\n",
+		"duplicate prompt": "prompts:\n  - name: team\n  - name: team\n",
+		"absolute path":    "files:\n  - path: /etc/passwd\n    template: files/auth.go.tmpl\n",
+		"escaping path":    "files:\n  - path: ../main.go\n    template: files/auth.go.tmpl\n",
+		"missing template": "files:\n  - path: auth.go\n    template: files/missing.tmpl\n",
+	} {
+		fsys := fstest.MapFS{
+			PackManifestFile:     {Data: []byte(manifest)},
+			"files/auth.go.tmpl": {Data: []byte("package middleware\n")},
+		}
+		if _, err := ParseTemplatePack(fsys); err == nil {
+			t.Errorf("%s: ParseTemplatePack() accepted an invalid pack", name)
+		}
+	}
+	if _, err := ParseTemplatePack(fstest.MapFS{}); err == nil {
+		t.Error("ParseTemplatePack() accepted a pack without a manifest")
+	}
+}
+
+func TestIsGitSource(t *testing.T) {
+	for source, want := range map[string]bool{
+		"https://github.com/acme/templates.git#v2": true,
+		"https://github.com/acme/templates":        true,
+		"git@github.com:acme/templates.git":        true,
+		"ssh://git@example.com/templates":          true,
+		"file:///srv/templates/acme.git":           true,
+		"../templates.git#main":                    true,
+		"./acme-templates":                         false,
+		"/srv/templates/acme":                      false,
+	} {
+		if got := isGitSource(source); got != want {
+			t.Errorf("isGitSource(%q) = %v, want %v", source, got, want)
+		}
+	}
+}
+
+// newPackRepo creates a git repository for a template pack and returns its
+// file:// URL and a function committing a version of the pack.
+func newPackRepo(t *testing.T) (url string, commit func(version string) string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	useTempCache(t)
+
+	dir := t.TempDir()
+	run := func(args ...string) string {
+		t.Helper()
+		out, err := git(context.Background(), dir, append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+	run("init", "--quiet")
+	commit = func(version string) string {
+		t.Helper()
+		files := map[string]string{
+			PackManifestFile:       "name: acme\n",
+			"project/main.go.tmpl": "package main // " + version + "\n",
+		}
+		for path, content := range files {
+			path = filepath.Join(dir, filepath.FromSlash(path))
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		run("add", ".")
+		run("commit", "--quiet", "-m", version)
+		return run("rev-parse", "HEAD")
+	}
+	return "file://" + dir, commit
+}
+
+func packMain(t *testing.T, pack *TemplatePack) string {
+	t.Helper()
+	data, err := fs.ReadFile(pack.FS, "project/main.go.tmpl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func TestLoadTemplatePackGit(t *testing.T) {
+	url, commit := newPackRepo(t)
+	v1 := commit("v1")
+	if _, err := git(context.Background(), strings.TrimPrefix(url, "file://"), "tag", "v1"); err != nil {
+		t.Fatal(err)
+	}
+	v2 := commit("v2")
+
+	pack, err := LoadTemplatePack(context.Background(), url, false)
+	if err != nil {
+		t.Fatalf("LoadTemplatePack(context.Background(), ) error = %v", err)
+	}
+	if pack.Commit != v2 || packMain(t, pack) != "package main // v2" || pack.Source != url {
+		t.Errorf("LoadTemplatePack(context.Background(), ) = %s at %s, want v2 at %s", packMain(t, pack), pack.Commit, v2)
+	}
+
+	tagged, err := LoadTemplatePack(context.Background(), url+"#v1", false)
+	if err != nil {
+		t.Fatalf("LoadTemplatePack(context.Background(), #v1) error = %v", err)
+	}
+	if tagged.Commit != v1 || packMain(t, tagged) != "package main // v1" {
+		t.Errorf("LoadTemplatePack(context.Background(), #v1) = %s at %s, want v1 at %s", packMain(t, tagged), tagged.Commit, v1)
+	}
+
+	// A pinned commit is checked out even after the source moved on, and
+	// is read from the cache once fetched
+	commit("v3")
+	pinned, err := LoadPinnedTemplatePack(context.Background(), url, v1, false)
+	if err != nil {
+		t.Fatalf("LoadPinnedTemplatePack() error = %v", err)
+	}
+	if pinned.Commit != v1 || packMain(t, pinned) != "package main // v1" {
+		t.Errorf("LoadPinnedTemplatePack() = %s at %s, want v1", packMain(t, pinned), pinned.Commit)
+	}
+	if err := os.RemoveAll(strings.TrimPrefix(url, "file://")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPinnedTemplatePack(context.Background(), url, v2, true); err != nil {
+		t.Errorf("LoadPinnedTemplatePack() of a cached commit error = %v", err)
+	}
+
+	if _, err := LoadPinnedTemplatePack(context.Background(), url, "../../etc", false); err == nil {
+		t.Error("LoadPinnedTemplatePack() accepted an invalid commit")
+	}
+}
+
+func TestLoadTemplatePackCanceled(t *testing.T) {
+	url, commit := newPackRepo(t)
+	commit("v1")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := LoadTemplatePack(ctx, url, false); !errors.Is(err, context.Canceled) {
+		t.Errorf("LoadTemplatePack() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestLoadTemplatePackOffline(t *testing.T) {
+	useTempCache(t)
+	rec := recordGit(t, nil)
+
+	source := "https://example.invalid/acme/templates.git#v2"
+	if _, err := LoadTemplatePack(context.Background(), source, true); err == nil || !strings.Contains(err.Error(), "not been fetched") {
+		t.Errorf("LoadTemplatePack(context.Background(), ) offline error = %v, want a not fetched error", err)
+	}
+	if _, err := LoadPinnedTemplatePack(context.Background(), source, "0123456789abcdef0123456789abcdef01234567", true); err == nil || !strings.Contains(err.Error(), "not cached") {
+		t.Errorf("LoadPinnedTemplatePack() offline error = %v, want a not cached error", err)
+	}
+	if lines := rec.Lines(); len(lines) > 0 {
+		t.Errorf("offline loads ran %q, want no git commands", lines)
+	}
+}
+
+func TestLoadTemplatePackGitCommands(t *testing.T) {
+	useTempCache(t)
+	const commit = "0123456789abcdef0123456789abcdef01234567"
+	rec := recordGit(t, func(c runner.Command) error {
+		switch c.Args[0] {
+		case "clone":
+			dir := c.Args[len(c.Args)-1]
+			return os.WriteFile(filepath.Join(dir, PackManifestFile), []byte("name: acme\n"), 0644)
+		case "rev-parse":
+			_, err := c.Stdout.Write([]byte(commit + "\n"))
+			return err
+		}
+		return nil
+	})
+
+	source := "https://example.com/acme/templates.git#v2"
+	pack, err := LoadTemplatePack(context.Background(), source, false)
+	if err != nil {
+		t.Fatalf("LoadTemplatePack() error = %v", err)
+	}
+	if pack.Name != "acme" || pack.Commit != commit {
+		t.Errorf("LoadTemplatePack() = %s at %s, want acme at %s", pack.Name, pack.Commit, commit)
+	}
+
+	commands := rec.Commands()
+	if len(commands) != 2 {
+		t.Fatalf("ran %q, want clone and rev-parse", rec.Lines())
+	}
+	clone := commands[0]
+	want := "git clone --quiet --depth 1 --branch v2 https://example.com/acme/templates.git " + clone.Args[len(clone.Args)-1]
+	if clone.String() != want {
+		t.Errorf("ran %q, want %q", clone.String(), want)
+	}
+	if len(clone.Env) != 1 || clone.Env[0] != "GIT_TERMINAL_PROMPT=0" {
+		t.Errorf("git env = %q, want prompts disabled", clone.Env)
+	}
+	if rev := commands[1]; rev.String() != "git rev-parse HEAD" || rev.Dir != clone.Args[len(clone.Args)-1] {
+		t.Errorf("ran %q in %s, want git rev-parse HEAD in the clone", rev.String(), rev.Dir)
+	}
+
+	// The fetched commit is cached, so offline loads don't run git
+	if _, err := LoadTemplatePack(context.Background(), source, true); err != nil {
+		t.Errorf("LoadTemplatePack() offline error = %v", err)
+	}
+	if len(rec.Commands()) != 2 {
+		t.Errorf("offline load ran %q, want no more git commands", rec.Lines()[2:])
+	}
+}
+
+func TestLoadTemplatePackWithoutGit(t *testing.T) {
+	useTempCache(t)
+	rec := recordGit(t, nil)
+	rec.Missing = []string{"git"}
+
+	_, err := LoadTemplatePack(context.Background(), "https://example.com/acme/templates.git", false)
+	if err == nil || !strings.Contains(err.Error(), "git is required") {
+		t.Errorf("LoadTemplatePack() error = %v, want git is required", err)
+	}
+}
+
+func TestLoadTemplatePackDir(t *testing.T) {
+	useTempCache(t)
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, PackManifestFile), []byte("name: acme\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	pack, err := LoadPinnedTemplatePack(context.Background(), dir, "0123456", true)
+	if err != nil {
+		t.Fatalf("LoadPinnedTemplatePack() error = %v", err)
+	}
+	if pack.Name != "acme" || pack.Commit != "" {
+		t.Errorf("LoadPinnedTemplatePack() = %+v, want acme without a commit", pack)
+	}
+	if _, err := LoadTemplatePack(context.Background(), filepath.Join(dir, "missing"), false); err == nil {
+		t.Error("LoadTemplatePack(context.Background(), ) accepted a missing directory")
+	}
+}
@@ -0,0 +1,387 @@
+package generator
+
+import (
+	"fmt"
+	"go/format"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/klass-lk/ginboot-cli/internal/manifest"
+)
+
+// Resource describes an entity scaffolded by `ginboot generate resource`.
+type Resource struct {
+	Name   string // Exported Go name, e.g. "OrderItem"
+	Var    string // Unexported Go name, e.g. "orderItem"
+	Plural string // Exported plural name, e.g. "OrderItems"
+	File   string // Snake-case file name stem, e.g. "order_item"
+	Table  string // Collection/table name, e.g. "order_items"
+	Route  string // Controller group name, e.g. "order-items"
+	Key    string // DynamoDB partition key, e.g. "ORDER_ITEM"
+	Fields []Field
+}
+
+// Field is a single model field of a Resource.
+type Field struct {
+	Name string // Exported Go field name, e.g. "CreatedAt"
+	Tag  string // Serialized name used in struct tags, e.g. "created_at"
+	Type string // Go type, e.g. "time.Time"
+}
+
+// FieldSpec returns the field spec the resource was created from, as
+// accepted by NewResource.
+func (r *Resource) FieldSpec() string {
+	specs := make([]string, len(r.Fields))
+	for i, f := range r.Fields {
+		specs[i] = f.Tag + ":" + f.Type
+	}
+	return strings.Join(specs, ",")
+}
+
+// HasTime reports whether any field needs the time package.
+func (r *Resource) HasTime() bool {
+	for _, f := range r.Fields {
+		if f.Type == "time.Time" {
+			return true
+		}
+	}
+	return false
+}
+
+var supportedFieldTypes = map[string]bool{
+	"string":    true,
+	"bool":      true,
+	"int":       true,
+	"int32":     true,
+	"int64":     true,
+	"float32":   true,
+	"float64":   true,
+	"time.Time": true,
+}
+
+var identifierPattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]*$`)
+
+// NewResource builds a Resource from a name such as "Order" and a field spec
+// such as "total:float64,status:string".
+func NewResource(name, fieldSpec string) (*Resource, error) {
+	if !identifierPattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid resource name '%s': must start with a letter and contain only letters, numbers and underscores", name)
+	}
+
+	exported := toCamel(name)
+	if keyword := lowerFirst(exported); token.IsKeyword(keyword) {
+		return nil, fmt.Errorf("invalid resource name '%s': its variables would be named %q, which is a Go keyword", name, keyword)
+	}
+	snake := toSnake(exported)
+	plural := pluralize(exported)
+	pluralSnake := toSnake(plural)
+
+	r := &Resource{
+		Name:   exported,
+		Var:    lowerFirst(exported),
+		Plural: plural,
+		File:   snake,
+		Table:  pluralSnake,
+		Route:  strings.ReplaceAll(pluralSnake, "_", "-"),
+		Key:    strings.ToUpper(snake),
+	}
+
+	fields, err := parseFields(fieldSpec)
+	if err != nil {
+		return nil, err
+	}
+	r.Fields = fields
+
+	return r, nil
+}
+
+func parseFields(spec string) ([]Field, error) {
+	var fields []Field
+	seen := map[string]bool{"ID": true}
+
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		name, typ, ok := strings.Cut(part, ":")
+		name = strings.TrimSpace(name)
+		typ = strings.TrimSpace(typ)
+		if !ok || name == "" || typ == "" {
+			return nil, fmt.Errorf("invalid field '%s': expected name:type", part)
+		}
+		if !identifierPattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid field name '%s'", name)
+		}
+		if !supportedFieldTypes[typ] {
+			return nil, fmt.Errorf("unsupported type '%s' for field '%s': must be one of string, bool, int, int32, int64, float32, float64, time.Time", typ, name)
+		}
+
+		goName := toCamel(name)
+		if seen[goName] {
+			return nil, fmt.Errorf("duplicate field '%s'", name)
+		}
+		seen[goName] = true
+
+		fields = append(fields, Field{Name: goName, Tag: name, Type: typ})
+	}
+
+	return fields, nil
+}
+
+// GenerateResource writes the resource's files, registers it in the di
+// container and records it with the files' checksums in m. Nothing is
+// written unless every file can be.
+func (g *ProjectGenerator) GenerateResource(m *manifest.Manifest, r *Resource) error {
+	container, err := os.ReadFile(filepath.Join(g.ProjectPath, containerFile))
+	if err != nil {
+		return fmt.Errorf("failed to read container: %w", err)
+	}
+
+	files, err := g.RenderResource(r, container)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if file.Path == containerFile {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(g.ProjectPath, file.Path)); err == nil {
+			return fmt.Errorf("%s already exists", file.Path)
+		}
+	}
+
+	updated := *m
+	updated.Files = make(map[string]string, len(m.Files)+len(files))
+	for path, sum := range m.Files {
+		updated.Files[path] = sum
+	}
+	for path, sum := range Checksums(files) {
+		updated.Files[path] = sum
+	}
+	updated.Resources = append(slices.Clone(m.Resources), manifest.Resource{Name: r.Name, Fields: r.FieldSpec()})
+	data, err := updated.Marshal()
+	if err != nil {
+		return err
+	}
+
+	return g.output().Write(append(files, File{Path: manifest.FileName, Content: data}), nil)
+}
+
+// renderResources renders the resources recorded in a manifest on top of
+// the rendered project files, registering each in the container.
+func (g *ProjectGenerator) renderResources(files []File, resources []manifest.Resource) ([]File, error) {
+	for _, spec := range resources {
+		r, err := NewResource(spec.Name, spec.Fields)
+		if err != nil {
+			return nil, fmt.Errorf("invalid resource %s in %s: %w", spec.Name, manifest.FileName, err)
+		}
+		i := slices.IndexFunc(files, func(f File) bool { return f.Path == containerFile })
+		if i < 0 {
+			return nil, fmt.Errorf("failed to render %s: %s is not generated", r.Name, containerFile)
+		}
+
+		rendered, err := g.RenderResource(r, files[i].Content)
+		if err != nil {
+			return nil, err
+		}
+		files = slices.Delete(files, i, i+1)
+		files = append(files, rendered...)
+	}
+	slices.SortFunc(files, func(a, b File) int { return strings.Compare(a.Path, b.Path) })
+	return files, nil
+}
+
+// containerFile is the generated di container resources are registered in.
+const containerFile = "internal/di/container.go"
+
+// RenderResource renders the files of a resource in memory, together with
+// the container source patched to register it.
+func (g *ProjectGenerator) RenderResource(r *Resource, container []byte) ([]File, error) {
+	files := map[string]string{
+		"internal/model/" + r.File + ".go":                 "resource/model.go.tmpl",
+		"internal/service/" + r.File + "_service.go":       "resource/service.go.tmpl",
+		"internal/controller/" + r.File + "_controller.go": "resource/controller.go.tmpl",
+	}
+	if g.hasRepository() {
+		files["internal/repository/"+r.File+"_repository.go"] = "resource/repository.go.tmpl"
+	}
+
+	patched, err := registerResource(string(container), g.DatabaseType, r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register %s in container: %w", r.Name, err)
+	}
+
+	data := g.templateData()
+	data.Resource = r
+	rendered, err := g.renderFiles(files, data, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(rendered, File{Path: containerFile, Content: patched}), nil
+}
+
+// registerResource adds the resource's repository, service and controller to
+// a generated di container and returns the gofmt'ed result.
+func registerResource(src, databaseType string, r *Resource) ([]byte, error) {
+	registered := regexp.MustCompile(`\b` + r.Name + `Service\s+service\.` + r.Name + `Service\b`)
+	if registered.MatchString(src) {
+		return nil, fmt.Errorf("%s is already registered", r.Name)
+	}
+
+	var repoType, repoInit string
+	switch databaseType {
+	case "none":
+		repoType = "*inmemory.InMemoryRepository[model." + r.Name + "]"
+		repoInit = "inmemory.NewInMemoryRepository[model." + r.Name + "]()"
+	case "dynamodb":
+		repoType = "*repository." + r.Name + "Repository"
+		repoInit = "repository.New" + r.Name + "Repository(client)"
+	default:
+		repoType = "*repository." + r.Name + "Repository"
+		repoInit = "repository.New" + r.Name + "Repository(db)"
+	}
+
+	var err error
+	src, err = insertBeforeStructEnd(src, "type Services struct {",
+		fmt.Sprintf("\t%sService service.%sService\n", r.Name, r.Name))
+	if err != nil {
+		return nil, err
+	}
+	src, err = insertBeforeStructEnd(src, "type Repository struct {",
+		fmt.Sprintf("\t%sRepository %s\n", r.Name, repoType))
+	if err != nil {
+		return nil, err
+	}
+
+	src, err = insertBeforeBlockEnd(src, "return &Repository{", "\n\t}",
+		fmt.Sprintf("\t\t%sRepository: %sRepository,\n", r.Name, r.Var))
+	if err != nil {
+		return nil, err
+	}
+	src, err = insertBeforeLine(src, "return &Repository{",
+		fmt.Sprintf("\t%sRepository := %s\n", r.Var, repoInit))
+	if err != nil {
+		return nil, err
+	}
+
+	src, err = insertBeforeBlockEnd(src, "return &Services{", "\n\t}",
+		fmt.Sprintf("\t\t%sService: %sService,\n", r.Name, r.Var))
+	if err != nil {
+		return nil, err
+	}
+	src, err = insertBeforeLine(src, "return &Services{",
+		fmt.Sprintf("\t%sService := service.New%sService(repos.%sRepository)\n", r.Var, r.Name, r.Name))
+	if err != nil {
+		return nil, err
+	}
+
+	src, err = insertBeforeStructEnd(src, "func InitializeControllers(", fmt.Sprintf(
+		"\t%sController := controller.New%sController(&services.%sService)\n\tengine.RegisterController(%q, %sController)\n",
+		r.Var, r.Name, r.Name, r.Route, r.Var))
+	if err != nil {
+		return nil, err
+	}
+
+	formatted, err := format.Source([]byte(src))
+	if err != nil {
+		return nil, fmt.Errorf("patched container is not valid Go: %w", err)
+	}
+	return formatted, nil
+}
+
+// insertBeforeStructEnd inserts text before the closing brace of the
+// top-level block that starts with header.
+func insertBeforeStructEnd(src, header, text string) (string, error) {
+	return insertBeforeBlockEnd(src, header, "\n}", text)
+}
+
+// insertBeforeBlockEnd inserts text as the last line of the block that
+// starts with header and ends with closing, dropping trailing blank lines.
+func insertBeforeBlockEnd(src, header, closing, text string) (string, error) {
+	start := strings.Index(src, header)
+	if start < 0 {
+		return "", fmt.Errorf("%q not found", strings.TrimSuffix(header, " {"))
+	}
+	end := strings.Index(src[start:], closing)
+	if end < 0 {
+		return "", fmt.Errorf("end of %q not found", strings.TrimSuffix(header, " {"))
+	}
+	end += start
+	body := strings.TrimRight(src[:end], " \t\n")
+	return body + "\n" + text + src[end+1:], nil
+}
+
+// insertBeforeLine inserts text on its own line before the line containing
+// marker.
+func insertBeforeLine(src, marker, text string) (string, error) {
+	idx := strings.Index(src, marker)
+	if idx < 0 {
+		return "", fmt.Errorf("%q not found", strings.TrimSuffix(marker, "{"))
+	}
+	lineStart := strings.LastIndex(src[:idx], "\n") + 1
+	return src[:lineStart] + text + src[lineStart:], nil
+}
+
+func toCamel(s string) string {
+	var b strings.Builder
+	upper := true
+	for _, c := range s {
+		if c == '_' || c == '-' {
+			upper = true
+			continue
+		}
+		if upper {
+			b.WriteRune(unicode.ToUpper(c))
+			upper = false
+		} else {
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
+
+func toSnake(s string) string {
+	var b strings.Builder
+	runes := []rune(s)
+	for i, c := range runes {
+		if unicode.IsUpper(c) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				b.WriteRune('_')
+			}
+			b.WriteRune(unicode.ToLower(c))
+		} else {
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	runes := []rune(s)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
+
+func pluralize(s string) string {
+	lower := strings.ToLower(s)
+	switch {
+	case strings.HasSuffix(lower, "y") && len(s) > 1 && !strings.ContainsRune("aeiou", rune(lower[len(lower)-2])):
+		return s[:len(s)-1] + "ies"
+	case strings.HasSuffix(lower, "s"), strings.HasSuffix(lower, "x"), strings.HasSuffix(lower, "z"),
+		strings.HasSuffix(lower, "ch"), strings.HasSuffix(lower, "sh"):
+		return s + "es"
+	default:
+		return s + "s"
+	}
+}
@@ -0,0 +1,59 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/klass-lk/ginboot-cli/internal/manifest"
+)
+
+func TestGenerateResourceChecksums(t *testing.T) {
+	dir := t.TempDir()
+	g := NewProjectGenerator(dir, "demo", "example.com/demo", "1.21", "postgres", "none", "http", false)
+	g.GinbootVersion = "v1.14.2"
+	if err := g.Generate(); err != nil {
+		t.Fatal(err)
+	}
+	m, err := manifest.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewResource("Order", "total:float64")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.GenerateResource(m, r); err != nil {
+		t.Fatalf("GenerateResource() error = %v", err)
+	}
+
+	saved, err := manifest.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{containerFile, "internal/model/order.go", "internal/repository/order_repository.go", "internal/service/order_service.go", "internal/controller/order_controller.go"} {
+		content, err := os.ReadFile(filepath.Join(dir, path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !saved.Unchanged(path, content) {
+			t.Errorf("%s: checksum %q does not match the written file", path, saved.Files[path])
+		}
+	}
+	if len(saved.Files) != len(m.Files)+4 {
+		t.Errorf("manifest has %d checksums, want %d", len(saved.Files), len(m.Files)+4)
+	}
+}
+
+func TestNewResourceKeyword(t *testing.T) {
+	for _, name := range []string{"Type", "func", "Go"} {
+		if _, err := NewResource(name, ""); err == nil || !strings.Contains(err.Error(), "Go keyword") {
+			t.Errorf("NewResource(%q) error = %v, want a Go keyword error", name, err)
+		}
+	}
+	if _, err := NewResource("Typed", ""); err != nil {
+		t.Errorf("NewResource(%q) error = %v", "Typed", err)
+	}
+}
@@ -0,0 +1,201 @@
+package generator
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/klass-lk/ginboot-cli/internal/manifest"
+)
+
+// Action is what an update does to a single file.
+type Action string
+
+const (
+	ActionCreate   Action = "create"
+	ActionUpdate   Action = "update"
+	ActionMerge    Action = "merge"
+	ActionConflict Action = "conflict"
+)
+
+// Change is a planned modification of one project file.
+type Change struct {
+	File     File
+	Action   Action
+	Existing []byte // Current on-disk content; nil when the file is new
+}
+
+// PlanUpdate compares the project rendered with g's options against the
+// project as previously generated (described by m) and the files on disk.
+// Both include the resources recorded in m, so their files are rendered for
+// the new options and they stay registered in the container. Files the new
+// options don't affect are left out. A file whose on-disk content no longer
+// matches what was generated is reported as a conflict, except go.mod, whose
+// new requirements are merged into the existing file.
+func (g *ProjectGenerator) PlanUpdate(m *manifest.Manifest) ([]Change, error) {
+	prev := NewProjectGeneratorFromManifest(g.ProjectPath, m)
+	prev.Templates = g.templates()
+	prev.Pack = g.Pack
+	previous, err := prev.Render()
+	if err == nil {
+		previous, err = prev.renderResources(previous, m.Resources)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to render existing project: %w", err)
+	}
+	before := make(map[string][]byte, len(previous))
+	for _, file := range previous {
+		before[file.Path] = file.Content
+	}
+
+	files, err := g.Render()
+	if err != nil {
+		return nil, err
+	}
+	files, err = g.renderResources(files, m.Resources)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []Change
+	for _, file := range files {
+		existing, err := os.ReadFile(filepath.Join(g.ProjectPath, file.Path))
+		if errors.Is(err, os.ErrNotExist) {
+			changes = append(changes, Change{File: file, Action: ActionCreate})
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file.Path, err)
+		}
+
+		old, rendered := before[file.Path]
+		if rendered && bytes.Equal(old, file.Content) {
+			// Not affected by the new options
+			continue
+		}
+		if bytes.Equal(existing, file.Content) {
+			continue
+		}
+
+		if file.Path == "go.mod" {
+			merged := mergeGoMod(existing, file.Content)
+			if !bytes.Equal(merged, existing) {
+				changes = append(changes, Change{File: File{Path: file.Path, Content: merged}, Action: ActionMerge, Existing: existing})
+			}
+			continue
+		}
+
+		pristine := m.Unchanged(file.Path, existing) || (rendered && bytes.Equal(existing, old))
+		action := ActionUpdate
+		if !pristine {
+			action = ActionConflict
+		}
+		changes = append(changes, Change{File: file, Action: action, Existing: existing})
+	}
+
+	return changes, nil
+}
+
+// ApplyUpdate writes the planned changes and saves the updated manifest.
+// Conflicting files are only overwritten when force is set. Either every
+// file is written or the project is left untouched.
+func (g *ProjectGenerator) ApplyUpdate(m *manifest.Manifest, changes []Change, force bool) error {
+	for _, change := range changes {
+		if change.Action == ActionConflict && !force {
+			return fmt.Errorf("%s was modified since it was generated", change.File.Path)
+		}
+	}
+
+	updated := g.Manifest()
+	updated.Resources = m.Resources
+	updated.Files = make(map[string]string, len(m.Files))
+	for path, sum := range m.Files {
+		updated.Files[path] = sum
+	}
+
+	files := make([]File, 0, len(changes)+1)
+	for _, change := range changes {
+		files = append(files, change.File)
+		if change.Action != ActionMerge {
+			updated.Files[change.File.Path] = manifest.Checksum(change.File.Content)
+		}
+	}
+
+	data, err := updated.Marshal()
+	if err != nil {
+		return err
+	}
+	files = append(files, File{Path: manifest.FileName, Content: data})
+
+	return g.output().Write(files, nil)
+}
+
+// mergeGoMod adds the requirements of rendered that are missing from
+// current, leaving everything else (including `go mod tidy` edits) intact.
+func mergeGoMod(current, rendered []byte) []byte {
+	have := map[string]bool{}
+	for _, req := range goModRequires(current) {
+		have[req[0]] = true
+	}
+
+	var missing []string
+	for _, req := range goModRequires(rendered) {
+		if !have[req[0]] {
+			missing = append(missing, "\t"+req[0]+" "+req[1])
+		}
+	}
+	if len(missing) == 0 {
+		return current
+	}
+
+	src := string(current)
+	if start := strings.Index(src, "require ("); start >= 0 {
+		if end := strings.Index(src[start:], "\n)"); end >= 0 {
+			end += start
+			return []byte(src[:end] + "\n" + strings.Join(missing, "\n") + src[end:])
+		}
+	}
+
+	if !strings.HasSuffix(src, "\n") {
+		src += "\n"
+	}
+	return []byte(src + "\nrequire (\n" + strings.Join(missing, "\n") + "\n)\n")
+}
+
+// goModRequires returns the [path, version] pairs required by a go.mod file.
+func goModRequires(data []byte) [][2]string {
+	var reqs [][2]string
+	inBlock := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+
+		switch {
+		case line == "require (":
+			inBlock = true
+			continue
+		case inBlock && line == ")":
+			inBlock = false
+			continue
+		case strings.HasPrefix(line, "require "):
+			line = strings.TrimSpace(strings.TrimPrefix(line, "require "))
+		case !inBlock:
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 2 {
+			reqs = append(reqs, [2]string{fields[0], fields[1]})
+		}
+	}
+
+	return reqs
+}
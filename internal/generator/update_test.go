@@ -0,0 +1,221 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/klass-lk/ginboot-cli/internal/manifest"
+)
+
+func TestMergeGoMod(t *testing.T) {
+	rendered := []byte(`module example.com/demo
+
+go 1.21
+
+require (
+	github.com/klass-lk/ginboot v1.14.2
+	github.com/lib/pq v1.10.9
+)
+`)
+
+	tests := []struct {
+		name    string
+		current string
+		want    string
+	}{
+		{
+			name: "block",
+			current: `module example.com/demo
+
+go 1.22
+
+require (
+	github.com/klass-lk/ginboot v1.15.0 // upgraded
+	golang.org/x/text v0.14.0 // indirect
+)
+`,
+			want: `module example.com/demo
+
+go 1.22
+
+require (
+	github.com/klass-lk/ginboot v1.15.0 // upgraded
+	golang.org/x/text v0.14.0 // indirect
+	github.com/lib/pq v1.10.9
+)
+`,
+		},
+		{
+			name: "single line",
+			current: `module example.com/demo
+
+require github.com/klass-lk/ginboot v1.14.2`,
+			want: `module example.com/demo
+
+require github.com/klass-lk/ginboot v1.14.2
+
+require (
+	github.com/lib/pq v1.10.9
+)
+`,
+		},
+		{
+			name: "up to date",
+			current: `module example.com/demo
+
+require (
+	github.com/klass-lk/ginboot v1.14.2
+	github.com/lib/pq v1.11.0
+)
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := tt.want
+			if want == "" {
+				want = tt.current
+			}
+			if got := string(mergeGoMod([]byte(tt.current), rendered)); got != want {
+				t.Errorf("mergeGoMod() =\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+// newUpdateProject generates a project with an Order resource into a new
+// directory and returns its manifest.
+func newUpdateProject(t *testing.T, database string) (string, *manifest.Manifest) {
+	t.Helper()
+
+	dir := t.TempDir()
+	g := NewProjectGenerator(dir, "demo", "example.com/demo", "1.21", database, "none", "http", false)
+	g.GinbootVersion = "v1.14.2"
+	if err := g.Generate(); err != nil {
+		t.Fatal(err)
+	}
+	m, err := manifest.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewResource("Order", "total:float64,placedAt:time.Time")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.GenerateResource(m, r); err != nil {
+		t.Fatal(err)
+	}
+	if m, err = manifest.Load(dir); err != nil {
+		t.Fatal(err)
+	}
+	return dir, m
+}
+
+func TestPlanUpdateResources(t *testing.T) {
+	dir, m := newUpdateProject(t, "none")
+	if len(m.Resources) != 1 || m.Resources[0] != (manifest.Resource{Name: "Order", Fields: "total:float64,placedAt:time.Time"}) {
+		t.Fatalf("manifest resources = %+v", m.Resources)
+	}
+
+	updated := *m
+	updated.Database = "mysql"
+	g := NewProjectGeneratorFromManifest(dir, &updated)
+	changes, err := g.PlanUpdate(m)
+	if err != nil {
+		t.Fatalf("PlanUpdate() error = %v", err)
+	}
+
+	actions := map[string]Action{}
+	var container []byte
+	for _, change := range changes {
+		actions[change.File.Path] = change.Action
+		if change.File.Path == containerFile {
+			container = change.File.Content
+		}
+	}
+	for path, want := range map[string]Action{
+		containerFile: ActionUpdate,
+		"internal/repository/order_repository.go": ActionCreate,
+		"internal/repository/user_repository.go":  ActionCreate,
+		"internal/service/order_service.go":       ActionUpdate,
+		"go.mod":                                  ActionMerge,
+	} {
+		if actions[path] != want {
+			t.Errorf("%s: action = %q, want %q (plan: %v)", path, actions[path], want, actions)
+		}
+	}
+	for _, registration := range []string{"repository.NewOrderRepository(db)", `engine.RegisterController("orders", orderController)`} {
+		if !strings.Contains(string(container), registration) {
+			t.Errorf("updated container lacks %s:\n%s", registration, container)
+		}
+	}
+
+	if err := g.ApplyUpdate(m, changes, false); err != nil {
+		t.Fatalf("ApplyUpdate() error = %v", err)
+	}
+	saved, err := manifest.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Database != "mysql" || len(saved.Resources) != 1 {
+		t.Errorf("saved manifest = %+v", saved)
+	}
+	written, err := os.ReadFile(filepath.Join(dir, containerFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !saved.Unchanged(containerFile, written) {
+		t.Error("the updated container's checksum was not recorded")
+	}
+}
+
+func TestPlanUpdateConflict(t *testing.T) {
+	dir, m := newUpdateProject(t, "postgres")
+
+	path := filepath.Join(dir, containerFile)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edited := append(content, []byte("\n// Edited by hand\n")...)
+	if err := os.WriteFile(path, edited, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	updated := *m
+	updated.Database = "mongodb"
+	g := NewProjectGeneratorFromManifest(dir, &updated)
+	changes, err := g.PlanUpdate(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conflicts := 0
+	for _, change := range changes {
+		if change.Action != ActionConflict {
+			continue
+		}
+		conflicts++
+		if change.File.Path != containerFile || string(change.Existing) != string(edited) {
+			t.Errorf("unexpected conflict in %s", change.File.Path)
+		}
+	}
+	if conflicts != 1 {
+		t.Errorf("PlanUpdate() reported %d conflicts, want 1 for %s", conflicts, containerFile)
+	}
+	if err := g.ApplyUpdate(m, changes, false); err == nil {
+		t.Error("ApplyUpdate() overwrote a hand-edited file without force")
+	}
+	if current, _ := os.ReadFile(path); string(current) != string(edited) {
+		t.Error("ApplyUpdate() changed the project although it failed")
+	}
+
+	// Changes the project options don't affect are not planned
+	unchanged := *m
+	g = NewProjectGeneratorFromManifest(dir, &unchanged)
+	if changes, err := g.PlanUpdate(m); err != nil || len(changes) != 0 {
+		t.Errorf("PlanUpdate() without changed options = %d changes, %v", len(changes), err)
+	}
+}
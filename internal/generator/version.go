@@ -0,0 +1,153 @@
+package generator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/klass-lk/ginboot-cli/internal/release"
+)
+
+// DefaultGinbootVersion is used when no other version source is available.
+const DefaultGinbootVersion = "v1.14.2"
+
+// GinbootVersionEnv pins the Ginboot framework version for generated projects.
+const GinbootVersionEnv = "GINBOOT_VERSION"
+
+// GinbootModule is the module path of the Ginboot framework.
+const GinbootModule = "github.com/klass-lk/ginboot"
+
+const (
+	defaultVersionCacheTTL = 24 * time.Hour
+	versionCacheFile       = "ginboot-version.json"
+)
+
+// VersionOptions controls how the Ginboot framework version is resolved.
+type VersionOptions struct {
+	Pinned   string         // Explicit version, e.g. from --ginboot-version
+	Offline  bool           // Never touch the network
+	Source   release.Source // Defaults to GitHub releases
+	CacheDir string         // Defaults to <user cache dir>/ginboot
+	CacheTTL time.Duration  // Defaults to 24h
+}
+
+// ResolvedVersion is a resolved Ginboot version and why it was chosen.
+type ResolvedVersion struct {
+	Version string
+	Reason  string
+}
+
+type versionCache struct {
+	Version   string    `json:"version"`
+	Source    string    `json:"source"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// ResolveGinbootVersion picks the Ginboot framework version for a project.
+// An explicit pin wins, then GINBOOT_VERSION, then a fresh cached lookup,
+// then the latest release from the release source. When offline or the
+// lookup fails, a stale cache entry or DefaultGinbootVersion is used. ctx
+// bounds the release lookup.
+func ResolveGinbootVersion(ctx context.Context, opts VersionOptions) ResolvedVersion {
+	if opts.Pinned != "" {
+		return ResolvedVersion{Version: normalizeVersion(opts.Pinned), Reason: "pinned via --ginboot-version"}
+	}
+	if env := os.Getenv(GinbootVersionEnv); env != "" {
+		return ResolvedVersion{Version: normalizeVersion(env), Reason: "pinned via " + GinbootVersionEnv}
+	}
+
+	if opts.Source == nil {
+		opts.Source = release.GitHub{}
+	}
+	if opts.CacheTTL == 0 {
+		opts.CacheTTL = defaultVersionCacheTTL
+	}
+	cachePath := versionCachePath(opts.CacheDir)
+	cached, cacheErr := readVersionCache(cachePath)
+	if cacheErr == nil && cached.Source != opts.Source.String() {
+		cacheErr = fmt.Errorf("cached release is from %s", cached.Source)
+	}
+
+	if opts.Offline {
+		if cacheErr == nil {
+			return ResolvedVersion{Version: cached.Version, Reason: fmt.Sprintf("offline: cached latest release from %s", cached.FetchedAt.Format(time.RFC3339))}
+		}
+		return ResolvedVersion{Version: DefaultGinbootVersion, Reason: "offline: no cached release, using built-in default"}
+	}
+
+	if cacheErr == nil && time.Since(cached.FetchedAt) < opts.CacheTTL {
+		return ResolvedVersion{Version: cached.Version, Reason: fmt.Sprintf("cached latest release (fetched %s ago)", time.Since(cached.FetchedAt).Round(time.Minute))}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	latest, err := opts.Source.Latest(ctx, GinbootModule)
+	if err == nil {
+		_ = writeVersionCache(cachePath, versionCache{Version: latest, Source: opts.Source.String(), FetchedAt: time.Now()})
+		return ResolvedVersion{Version: latest, Reason: "latest release from " + opts.Source.String()}
+	}
+
+	if cacheErr == nil {
+		return ResolvedVersion{Version: cached.Version, Reason: fmt.Sprintf("release lookup failed (%v), using cached release from %s", err, cached.FetchedAt.Format(time.RFC3339))}
+	}
+	return ResolvedVersion{Version: DefaultGinbootVersion, Reason: fmt.Sprintf("release lookup failed (%v), using built-in default", err)}
+}
+
+func normalizeVersion(v string) string {
+	v = strings.TrimSpace(v)
+	if !strings.HasPrefix(v, "v") {
+		v = "v" + v
+	}
+	return v
+}
+
+func versionCachePath(dir string) string {
+	if dir == "" {
+		base, err := os.UserCacheDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(base, "ginboot")
+	}
+	return filepath.Join(dir, versionCacheFile)
+}
+
+func readVersionCache(path string) (versionCache, error) {
+	var cache versionCache
+	if path == "" {
+		return cache, fmt.Errorf("no cache directory")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cache, err
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return cache, err
+	}
+	if cache.Version == "" {
+		return cache, fmt.Errorf("empty cache entry")
+	}
+
+	return cache, nil
+}
+
+func writeVersionCache(path string, cache versionCache) error {
+	if path == "" {
+		return fmt.Errorf("no cache directory")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,123 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSource is a release source that counts its lookups.
+type fakeSource struct {
+	version string
+	err     error
+	calls   int
+}
+
+func (s *fakeSource) String() string { return "fake releases" }
+
+func (s *fakeSource) Latest(ctx context.Context, module string) (string, error) {
+	s.calls++
+	return s.version, s.err
+}
+
+func TestResolveGinbootVersionPrecedence(t *testing.T) {
+	source := &fakeSource{version: "v1.20.0"}
+	opts := VersionOptions{Source: source, CacheDir: t.TempDir()}
+
+	t.Setenv(GinbootVersionEnv, "1.16.0")
+	pinned := opts
+	pinned.Pinned = "1.15.0"
+	if got := ResolveGinbootVersion(context.Background(), pinned); got.Version != "v1.15.0" || !strings.Contains(got.Reason, "--ginboot-version") {
+		t.Errorf("pinned version = %+v, want v1.15.0 from --ginboot-version", got)
+	}
+	if got := ResolveGinbootVersion(context.Background(), opts); got.Version != "v1.16.0" || !strings.Contains(got.Reason, GinbootVersionEnv) {
+		t.Errorf("version = %+v, want v1.16.0 from %s", got, GinbootVersionEnv)
+	}
+	if source.calls != 0 {
+		t.Errorf("pinned versions looked up the latest release %d times", source.calls)
+	}
+
+	t.Setenv(GinbootVersionEnv, "")
+	if got := ResolveGinbootVersion(context.Background(), opts); got.Version != "v1.20.0" || !strings.Contains(got.Reason, "latest release") {
+		t.Errorf("version = %+v, want the latest release", got)
+	}
+	source.version = "v1.21.0"
+	if got := ResolveGinbootVersion(context.Background(), opts); got.Version != "v1.20.0" || !strings.Contains(got.Reason, "cached") {
+		t.Errorf("version = %+v, want the cached release", got)
+	}
+	if source.calls != 1 {
+		t.Errorf("looked up the latest release %d times, want once", source.calls)
+	}
+}
+
+func TestResolveGinbootVersionCacheTTL(t *testing.T) {
+	source := &fakeSource{version: "v1.20.0"}
+	opts := VersionOptions{Source: source, CacheDir: t.TempDir(), CacheTTL: time.Hour}
+	ResolveGinbootVersion(context.Background(), opts)
+
+	// Age the cache entry past the TTL
+	path := versionCachePath(opts.CacheDir)
+	cache, err := readVersionCache(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache.FetchedAt = time.Now().Add(-2 * time.Hour)
+	if err := writeVersionCache(path, cache); err != nil {
+		t.Fatal(err)
+	}
+
+	source.version = "v1.21.0"
+	if got := ResolveGinbootVersion(context.Background(), opts); got.Version != "v1.21.0" {
+		t.Errorf("version = %+v, want a fresh lookup after the TTL", got)
+	}
+
+	// An expired entry is still better than the default when the lookup fails
+	cache.FetchedAt = time.Now().Add(-2 * time.Hour)
+	if err := writeVersionCache(path, cache); err != nil {
+		t.Fatal(err)
+	}
+	source.err = errors.New("network unreachable")
+	if got := ResolveGinbootVersion(context.Background(), opts); got.Version != "v1.20.0" || !strings.Contains(got.Reason, "network unreachable") {
+		t.Errorf("version = %+v, want the stale cached release", got)
+	}
+
+	// Entries of another release source are ignored
+	cache.Source = "GitHub releases"
+	cache.FetchedAt = time.Now()
+	if err := writeVersionCache(path, cache); err != nil {
+		t.Fatal(err)
+	}
+	if got := ResolveGinbootVersion(context.Background(), opts); got.Version != DefaultGinbootVersion {
+		t.Errorf("version = %+v, want the built-in default", got)
+	}
+}
+
+func TestResolveGinbootVersionOffline(t *testing.T) {
+	source := &fakeSource{version: "v1.20.0"}
+	opts := VersionOptions{Source: source, CacheDir: t.TempDir(), Offline: true}
+
+	if got := ResolveGinbootVersion(context.Background(), opts); got.Version != DefaultGinbootVersion || !strings.Contains(got.Reason, "offline") {
+		t.Errorf("offline version without a cache = %+v, want the built-in default", got)
+	}
+
+	opts.Offline = false
+	ResolveGinbootVersion(context.Background(), opts)
+	source.version = "v1.21.0"
+	opts.Offline = true
+	opts.CacheTTL = time.Nanosecond
+	if got := ResolveGinbootVersion(context.Background(), opts); got.Version != "v1.20.0" || !strings.Contains(got.Reason, "offline") {
+		t.Errorf("offline version = %+v, want the cached release however old", got)
+	}
+	if source.calls != 1 {
+		t.Errorf("offline resolution looked up the latest release")
+	}
+}
+
+func TestProjectFilesRequiresVersion(t *testing.T) {
+	g := NewProjectGenerator(t.TempDir(), "demo", "example.com/demo", "1.21", "none", "none", "http", false)
+	if _, err := g.ProjectFiles(); err == nil {
+		t.Error("ProjectFiles() without a Ginboot version succeeded")
+	}
+}
@@ -0,0 +1,153 @@
+package generator
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Writer receives a generated project. Files and dirs are relative to the
+// project root, with forward slashes; dirs lists directories to create even
+// when no file is written to them.
+type Writer interface {
+	Write(files []File, dirs []string) error
+}
+
+// DirWriter writes into a directory on disk. Either every file is written
+// or the directory is left as it was.
+type DirWriter struct {
+	Root string
+}
+
+func (w DirWriter) Write(files []File, dirs []string) error {
+	return commitFiles(w.Root, files, dirs...)
+}
+
+// MemoryWriter keeps the generated project in memory, e.g. to serve it or
+// inspect it in tests.
+type MemoryWriter struct {
+	Files map[string][]byte
+	Dirs  []string
+}
+
+func (w *MemoryWriter) Write(files []File, dirs []string) error {
+	if w.Files == nil {
+		w.Files = map[string][]byte{}
+	}
+	for _, file := range files {
+		w.Files[file.Path] = file.Content
+	}
+	w.Dirs = append(w.Dirs, dirs...)
+	return nil
+}
+
+// ZipWriter writes the project as a zip archive with every entry below
+// Prefix (typically the project name).
+type ZipWriter struct {
+	W      io.Writer
+	Prefix string
+}
+
+func (w ZipWriter) Write(files []File, dirs []string) error {
+	zw := zip.NewWriter(w.W)
+	now := time.Now()
+
+	for _, dir := range archiveDirs(w.Prefix, files, dirs) {
+		header := &zip.FileHeader{Name: dir, Modified: now}
+		header.SetMode(fs.ModeDir | 0755)
+		if _, err := zw.CreateHeader(header); err != nil {
+			return fmt.Errorf("failed to write %s: %w", dir, err)
+		}
+	}
+	for _, file := range files {
+		header := &zip.FileHeader{Name: archivePath(w.Prefix, file.Path), Method: zip.Deflate, Modified: now}
+		header.SetMode(0644)
+		fw, err := zw.CreateHeader(header)
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %w", file.Path, err)
+		}
+		if _, err := fw.Write(file.Content); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file.Path, err)
+		}
+	}
+
+	return zw.Close()
+}
+
+// TarGzWriter writes the project as a gzip-compressed tar archive with
+// every entry below Prefix (typically the project name).
+type TarGzWriter struct {
+	W      io.Writer
+	Prefix string
+}
+
+func (w TarGzWriter) Write(files []File, dirs []string) error {
+	gz := gzip.NewWriter(w.W)
+	tw := tar.NewWriter(gz)
+	now := time.Now()
+
+	for _, dir := range archiveDirs(w.Prefix, files, dirs) {
+		header := &tar.Header{Typeflag: tar.TypeDir, Name: dir, Mode: 0755, ModTime: now}
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write %s: %w", dir, err)
+		}
+	}
+	for _, file := range files {
+		header := &tar.Header{Typeflag: tar.TypeReg, Name: archivePath(w.Prefix, file.Path), Mode: 0644, Size: int64(len(file.Content)), ModTime: now}
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file.Path, err)
+		}
+		if _, err := tw.Write(file.Content); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file.Path, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// archiveDirs returns the archive entry names of every directory needed for
+// the files and dirs, parents first.
+func archiveDirs(prefix string, files []File, dirs []string) []string {
+	seen := map[string]bool{}
+	var add func(dir string)
+	add = func(dir string) {
+		if dir == "." || dir == "" || seen[dir] {
+			return
+		}
+		add(path.Dir(dir))
+		seen[dir] = true
+	}
+	for _, dir := range dirs {
+		add(dir)
+	}
+	for _, file := range files {
+		add(path.Dir(file.Path))
+	}
+
+	all := make([]string, 0, len(seen)+1)
+	if prefix != "" {
+		all = append(all, strings.TrimSuffix(prefix, "/")+"/")
+	}
+	for dir := range seen {
+		all = append(all, archivePath(prefix, dir)+"/")
+	}
+	sort.Strings(all)
+	return all
+}
+
+func archivePath(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return strings.TrimSuffix(prefix, "/") + "/" + name
+}
@@ -0,0 +1,166 @@
+package lambdalocal
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ProxyRequest is the API Gateway REST API proxy integration event, as sent
+// to a function behind a /{proxy+} resource.
+type ProxyRequest struct {
+	Resource                        string              `json:"resource"`
+	Path                            string              `json:"path"`
+	HTTPMethod                      string              `json:"httpMethod"`
+	Headers                         map[string]string   `json:"headers"`
+	MultiValueHeaders               map[string][]string `json:"multiValueHeaders"`
+	QueryStringParameters           map[string]string   `json:"queryStringParameters"`
+	MultiValueQueryStringParameters map[string][]string `json:"multiValueQueryStringParameters"`
+	PathParameters                  map[string]string   `json:"pathParameters"`
+	StageVariables                  map[string]string   `json:"stageVariables"`
+	RequestContext                  ProxyRequestContext `json:"requestContext"`
+	Body                            string              `json:"body"`
+	IsBase64Encoded                 bool                `json:"isBase64Encoded"`
+}
+
+// ProxyRequestContext is the requestContext of a ProxyRequest.
+type ProxyRequestContext struct {
+	AccountID        string        `json:"accountId"`
+	APIID            string        `json:"apiId"`
+	ResourceID       string        `json:"resourceId"`
+	ResourcePath     string        `json:"resourcePath"`
+	Stage            string        `json:"stage"`
+	RequestID        string        `json:"requestId"`
+	HTTPMethod       string        `json:"httpMethod"`
+	Path             string        `json:"path"`
+	Protocol         string        `json:"protocol"`
+	RequestTime      string        `json:"requestTime"`
+	RequestTimeEpoch int64         `json:"requestTimeEpoch"`
+	Identity         ProxyIdentity `json:"identity"`
+}
+
+// ProxyIdentity is the caller identity of a ProxyRequest.
+type ProxyIdentity struct {
+	SourceIP  string `json:"sourceIp"`
+	UserAgent string `json:"userAgent"`
+}
+
+// ProxyResponse is what a function behind an API Gateway proxy integration
+// returns.
+type ProxyResponse struct {
+	StatusCode        int                 `json:"statusCode"`
+	Headers           map[string]string   `json:"headers"`
+	MultiValueHeaders map[string][]string `json:"multiValueHeaders"`
+	Body              string              `json:"body"`
+	IsBase64Encoded   bool                `json:"isBase64Encoded"`
+}
+
+// NewProxyRequest translates an HTTP request into the event API Gateway
+// sends for it to a /{proxy+} resource of the given stage.
+func NewProxyRequest(r *http.Request, stage string) (*ProxyRequest, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+
+	now := time.Now()
+	event := &ProxyRequest{
+		Resource:       "/{proxy+}",
+		Path:           r.URL.Path,
+		HTTPMethod:     r.Method,
+		PathParameters: map[string]string{"proxy": strings.TrimPrefix(r.URL.Path, "/")},
+		RequestContext: ProxyRequestContext{
+			AccountID:        "123456789012",
+			APIID:            "local",
+			ResourceID:       "local",
+			ResourcePath:     "/{proxy+}",
+			Stage:            stage,
+			RequestID:        newRequestID(),
+			HTTPMethod:       r.Method,
+			Path:             "/" + stage + r.URL.Path,
+			Protocol:         r.Proto,
+			RequestTime:      now.UTC().Format("02/Jan/2006:15:04:05 -0700"),
+			RequestTimeEpoch: now.UnixMilli(),
+			Identity:         ProxyIdentity{SourceIP: sourceIP(r), UserAgent: r.UserAgent()},
+		},
+	}
+
+	// API Gateway sends null instead of empty maps
+	if len(r.Header) > 0 || r.Host != "" {
+		event.Headers = map[string]string{}
+		event.MultiValueHeaders = map[string][]string{}
+		for name, values := range r.Header {
+			event.Headers[name] = values[len(values)-1]
+			event.MultiValueHeaders[name] = values
+		}
+		if r.Host != "" {
+			event.Headers["Host"] = r.Host
+			event.MultiValueHeaders["Host"] = []string{r.Host}
+		}
+	}
+	if query := r.URL.Query(); len(query) > 0 {
+		event.QueryStringParameters = map[string]string{}
+		event.MultiValueQueryStringParameters = map[string][]string{}
+		for name, values := range query {
+			event.QueryStringParameters[name] = values[len(values)-1]
+			event.MultiValueQueryStringParameters[name] = values
+		}
+	}
+
+	if utf8.Valid(body) {
+		event.Body = string(body)
+	} else {
+		event.Body = base64.StdEncoding.EncodeToString(body)
+		event.IsBase64Encoded = true
+	}
+	return event, nil
+}
+
+func sourceIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// WriteProxyResponse writes the response of a function behind an API
+// Gateway proxy integration to w.
+func WriteProxyResponse(w http.ResponseWriter, payload []byte) error {
+	var resp ProxyResponse
+	if err := json.Unmarshal(payload, &resp); err != nil {
+		return fmt.Errorf("function returned an invalid API Gateway proxy response: %w", err)
+	}
+	if resp.StatusCode == 0 {
+		return fmt.Errorf("function returned an invalid API Gateway proxy response: no statusCode")
+	}
+
+	body := []byte(resp.Body)
+	if resp.IsBase64Encoded {
+		decoded, err := base64.StdEncoding.DecodeString(resp.Body)
+		if err != nil {
+			return fmt.Errorf("function returned an invalid base64 body: %w", err)
+		}
+		body = decoded
+	}
+
+	h := w.Header()
+	for name, value := range resp.Headers {
+		h.Set(name, value)
+	}
+	for name, values := range resp.MultiValueHeaders {
+		h.Del(name)
+		for _, value := range values {
+			h.Add(name, value)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	_, err := w.Write(body)
+	return err
+}
@@ -0,0 +1,261 @@
+// Package lambdalocal emulates the AWS Lambda Runtime API, so that a
+// function built for the provided runtime can be invoked on a laptop without
+// SAM CLI or Docker: the function's bootstrap runs as a local process with
+// AWS_LAMBDA_RUNTIME_API pointing at an Emulator.
+package lambdalocal
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// runtimePrefix is the path of the Runtime API version the emulator serves.
+const runtimePrefix = "/2018-06-01/runtime/"
+
+// Response is the result of an invocation.
+type Response struct {
+	RequestID string
+	Payload   []byte
+	// Error is set when the function reported an error instead of a
+	// response; Payload is then the error document.
+	Error    *FunctionError
+	Duration time.Duration
+}
+
+// FunctionError is an error reported by the function.
+type FunctionError struct {
+	Type       string   `json:"errorType"`
+	Message    string   `json:"errorMessage"`
+	StackTrace []string `json:"stackTrace,omitempty"`
+}
+
+func (e *FunctionError) Error() string {
+	if e.Type == "" {
+		return e.Message
+	}
+	return e.Type + ": " + e.Message
+}
+
+// Emulator serves the Runtime API to a single function process, which
+// handles one invocation at a time like a Lambda execution environment.
+type Emulator struct {
+	// FunctionARN is passed to the function with every invocation.
+	FunctionARN string
+
+	invoke      sync.Mutex // Held for the duration of an invocation
+	invocations chan *invocation
+
+	mu       sync.Mutex
+	pending  map[string]*invocation
+	initErr  *FunctionError
+	listener net.Listener
+	server   *http.Server
+}
+
+type invocation struct {
+	id       string
+	payload  []byte
+	deadline time.Time
+	started  time.Time
+	done     chan *Response // Buffered; receives the single response
+}
+
+// New returns an emulator for the function with the given ARN.
+func New(functionARN string) *Emulator {
+	return &Emulator{
+		FunctionARN: functionARN,
+		invocations: make(chan *invocation),
+		pending:     map[string]*invocation{},
+	}
+}
+
+// Start listens on addr, e.g. "127.0.0.1:0", and serves the Runtime API in
+// the background. It returns the address to set AWS_LAMBDA_RUNTIME_API to.
+func (e *Emulator) Start(addr string) (string, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return "", err
+	}
+	e.mu.Lock()
+	e.listener = listener
+	e.server = &http.Server{Handler: e}
+	e.mu.Unlock()
+
+	go e.server.Serve(listener)
+	return listener.Addr().String(), nil
+}
+
+// Close stops serving the Runtime API.
+func (e *Emulator) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.server == nil {
+		return nil
+	}
+	return e.server.Close()
+}
+
+// InitError returns the error the function reported while initializing,
+// if any.
+func (e *Emulator) InitError() *FunctionError {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.initErr
+}
+
+// Invoke passes payload to the function once it asks for the next
+// invocation and waits for its response. The deadline of ctx, if any, is
+// the function's deadline.
+func (e *Emulator) Invoke(ctx context.Context, payload []byte) (*Response, error) {
+	e.invoke.Lock()
+	defer e.invoke.Unlock()
+
+	inv := &invocation{id: newRequestID(), payload: payload, done: make(chan *Response, 1)}
+	if deadline, ok := ctx.Deadline(); ok {
+		inv.deadline = deadline
+	} else {
+		inv.deadline = time.Now().Add(15 * time.Minute)
+	}
+	e.mu.Lock()
+	e.pending[inv.id] = inv
+	e.mu.Unlock()
+	defer func() {
+		e.mu.Lock()
+		delete(e.pending, inv.id)
+		e.mu.Unlock()
+	}()
+
+	select {
+	case e.invocations <- inv:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("function did not ask for the invocation: %w", context.Cause(ctx))
+	}
+	select {
+	case resp := <-inv.done:
+		return resp, nil
+	case <-ctx.Done():
+		// The function may have answered just before ctx was done
+		select {
+		case resp := <-inv.done:
+			return resp, nil
+		default:
+		}
+		return nil, fmt.Errorf("request %s: %w", inv.id, context.Cause(ctx))
+	}
+}
+
+// ServeHTTP implements the Runtime API.
+func (e *Emulator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, runtimePrefix)
+	switch {
+	case r.Method == http.MethodGet && path == "invocation/next":
+		e.next(w, r)
+	case r.Method == http.MethodPost && path == "init/error":
+		var ferr FunctionError
+		if err := readJSON(r, &ferr); err != nil {
+			writeError(w, http.StatusBadRequest, "InvalidRequest", err.Error())
+			return
+		}
+		e.mu.Lock()
+		e.initErr = &ferr
+		e.mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	case r.Method == http.MethodPost && strings.HasPrefix(path, "invocation/"):
+		id, action, _ := strings.Cut(strings.TrimPrefix(path, "invocation/"), "/")
+		switch action {
+		case "response", "error":
+			e.complete(w, r, id, action == "error")
+		default:
+			writeError(w, http.StatusNotFound, "NotFound", "unknown Runtime API path "+r.URL.Path)
+		}
+	default:
+		writeError(w, http.StatusNotFound, "NotFound", "unknown Runtime API path "+r.URL.Path)
+	}
+}
+
+// next waits for an invocation and hands it to the function.
+func (e *Emulator) next(w http.ResponseWriter, r *http.Request) {
+	var inv *invocation
+	select {
+	case inv = <-e.invocations:
+	case <-r.Context().Done():
+		return
+	}
+	inv.started = time.Now()
+
+	h := w.Header()
+	h.Set("Content-Type", "application/json")
+	h.Set("Lambda-Runtime-Aws-Request-Id", inv.id)
+	h.Set("Lambda-Runtime-Deadline-Ms", strconv.FormatInt(inv.deadline.UnixMilli(), 10))
+	h.Set("Lambda-Runtime-Invoked-Function-Arn", e.FunctionARN)
+	h.Set("Lambda-Runtime-Trace-Id", "Root=1-"+strconv.FormatInt(inv.started.Unix(), 16)+"-"+strings.ReplaceAll(inv.id, "-", "")[:24]+";Sampled=0")
+	w.WriteHeader(http.StatusOK)
+	w.Write(inv.payload)
+}
+
+// complete delivers the response or error of an invocation.
+func (e *Emulator) complete(w http.ResponseWriter, r *http.Request, id string, failed bool) {
+	e.mu.Lock()
+	inv, ok := e.pending[id]
+	e.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusBadRequest, "InvalidRequestID", "unknown request ID "+id)
+		return
+	}
+
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", err.Error())
+		return
+	}
+	resp := &Response{RequestID: id, Payload: payload, Duration: time.Since(inv.started)}
+	if failed {
+		resp.Error = &FunctionError{}
+		if err := json.Unmarshal(payload, resp.Error); err != nil || resp.Error.Message == "" && resp.Error.Type == "" {
+			resp.Error = &FunctionError{Type: r.Header.Get("Lambda-Runtime-Function-Error-Type"), Message: strings.TrimSpace(string(payload))}
+		}
+	}
+
+	select {
+	case inv.done <- resp:
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		writeError(w, http.StatusBadRequest, "InvalidStateTransition", "request "+id+" was already answered")
+	}
+}
+
+func readJSON(r *http.Request, v any) error {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return errors.New("invalid JSON: " + err.Error())
+	}
+	return nil
+}
+
+func writeError(w http.ResponseWriter, status int, errorType, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(FunctionError{Type: errorType, Message: message})
+}
+
+// newRequestID returns a random UUID, the format of Lambda request IDs.
+func newRequestID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
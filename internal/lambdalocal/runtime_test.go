@@ -0,0 +1,145 @@
+package lambdalocal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// serveOne plays a function's runtime loop for one invocation against the
+// Runtime API at base, answering with handle.
+func serveOne(t *testing.T, base string, handle func(event []byte, header http.Header) (action string, body []byte)) {
+	t.Helper()
+
+	resp, err := http.Get(base + "/2018-06-01/runtime/invocation/next")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	event, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	id := resp.Header.Get("Lambda-Runtime-Aws-Request-Id")
+	action, body := handle(event, resp.Header)
+	resp, err = http.Post(base+"/2018-06-01/runtime/invocation/"+id+"/"+action, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Errorf("posting the %s returned %s", action, resp.Status)
+	}
+}
+
+func TestInvoke(t *testing.T) {
+	emu := New("arn:aws:lambda:us-east-1:123456789012:function:demo")
+	server := httptest.NewServer(emu)
+	defer server.Close()
+
+	go serveOne(t, server.URL, func(event []byte, header http.Header) (string, []byte) {
+		if header.Get("Lambda-Runtime-Invoked-Function-Arn") != emu.FunctionARN {
+			t.Errorf("function ARN header = %q", header.Get("Lambda-Runtime-Invoked-Function-Arn"))
+		}
+		if header.Get("Lambda-Runtime-Deadline-Ms") == "" {
+			t.Error("no deadline header")
+		}
+		return "response", append([]byte(`{"echo":`), append(event, '}')...)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	resp, err := emu.Invoke(ctx, []byte(`{"name":"demo"}`))
+	if err != nil {
+		t.Fatalf("Invoke() error = %v", err)
+	}
+	if resp.Error != nil || string(resp.Payload) != `{"echo":{"name":"demo"}}` {
+		t.Errorf("Invoke() = %s, %v", resp.Payload, resp.Error)
+	}
+}
+
+func TestInvokeFunctionError(t *testing.T) {
+	emu := New("demo")
+	server := httptest.NewServer(emu)
+	defer server.Close()
+
+	go serveOne(t, server.URL, func([]byte, http.Header) (string, []byte) {
+		return "error", []byte(`{"errorType":"*errors.errorString","errorMessage":"boom"}`)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	resp, err := emu.Invoke(ctx, []byte(`{}`))
+	if err != nil {
+		t.Fatalf("Invoke() error = %v", err)
+	}
+	if resp.Error == nil || resp.Error.Error() != "*errors.errorString: boom" {
+		t.Errorf("Invoke() error = %v, want the function's error", resp.Error)
+	}
+}
+
+func TestInvokeTimeout(t *testing.T) {
+	emu := New("demo")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := emu.Invoke(ctx, []byte(`{}`)); err == nil || !strings.Contains(err.Error(), "deadline exceeded") {
+		t.Errorf("Invoke() error = %v, want a timeout", err)
+	}
+}
+
+func TestProxyRoundTrip(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/v1/users?tag=a&tag=b", strings.NewReader(`{"name":"Ada"}`))
+	r.Header.Add("Accept", "application/json")
+	r.Header.Add("X-Trace", "1")
+	r.Header.Add("X-Trace", "2")
+
+	event, err := NewProxyRequest(r, "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Path != "/api/v1/users" || event.PathParameters["proxy"] != "api/v1/users" || event.RequestContext.Path != "/prod/api/v1/users" {
+		t.Errorf("paths = %q, %q, %q", event.Path, event.PathParameters["proxy"], event.RequestContext.Path)
+	}
+	if event.QueryStringParameters["tag"] != "b" || len(event.MultiValueQueryStringParameters["tag"]) != 2 {
+		t.Errorf("query = %v, %v", event.QueryStringParameters, event.MultiValueQueryStringParameters)
+	}
+	if event.Headers["X-Trace"] != "2" || len(event.MultiValueHeaders["X-Trace"]) != 2 || event.Headers["Host"] != "localhost:3000" {
+		t.Errorf("headers = %v, %v", event.Headers, event.MultiValueHeaders)
+	}
+	if event.Body != `{"name":"Ada"}` || event.IsBase64Encoded {
+		t.Errorf("body = %q, base64 = %v", event.Body, event.IsBase64Encoded)
+	}
+
+	binary, err := NewProxyRequest(httptest.NewRequest(http.MethodPut, "/files", bytes.NewReader([]byte{0xff, 0xfe})), "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !binary.IsBase64Encoded || binary.Body != "//4=" {
+		t.Errorf("binary body = %q, base64 = %v", binary.Body, binary.IsBase64Encoded)
+	}
+
+	payload, _ := json.Marshal(ProxyResponse{
+		StatusCode:        http.StatusCreated,
+		Headers:           map[string]string{"Content-Type": "application/json"},
+		MultiValueHeaders: map[string][]string{"Set-Cookie": {"a=1", "b=2"}},
+		Body:              `{"id":"1"}`,
+	})
+	w := httptest.NewRecorder()
+	if err := WriteProxyResponse(w, payload); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusCreated || w.Body.String() != `{"id":"1"}` || len(w.Header().Values("Set-Cookie")) != 2 {
+		t.Errorf("response = %d %v %q", w.Code, w.Header(), w.Body.String())
+	}
+
+	if err := WriteProxyResponse(httptest.NewRecorder(), []byte(`"not a proxy response"`)); err == nil {
+		t.Error("WriteProxyResponse() accepted a payload that is not a proxy response")
+	}
+}
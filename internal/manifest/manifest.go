@@ -0,0 +1,119 @@
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+)
+
+// FileName is the name of the project manifest written by `ginboot new`.
+const FileName = "ginboot.yaml"
+
+// ErrNotFound is returned by Load when the directory has no manifest.
+var ErrNotFound = errors.New(FileName + " not found")
+
+// Manifest records how a project was generated so later commands can branch
+// on the project's real stack.
+type Manifest struct {
+	ProjectName    string `yaml:"project_name"`
+	ModuleName     string `yaml:"module"`
+	GoVersion      string `yaml:"go_version"`
+	GinbootVersion string `yaml:"ginboot_version"`
+	Database       string `yaml:"database"`
+	Storage        string `yaml:"storage"`
+	Deploy         string `yaml:"deploy"`
+	Telemetry      bool   `yaml:"telemetry"`
+
+	// Arch and Runtime are the Lambda architecture and runtime of lambda
+	// projects; empty in manifests from before they were configurable,
+	// which used x86_64 on provided.al2.
+	Arch    string `yaml:"arch,omitempty"`
+	Runtime string `yaml:"runtime,omitempty"`
+
+	// Template is the template pack the project was generated from, if any.
+	Template *Template `yaml:"template,omitempty"`
+
+	// Resources are the resources added with `ginboot generate resource`,
+	// so updates can render them for the project's new options.
+	Resources []Resource `yaml:"resources,omitempty"`
+
+	// Files maps each generated file to the checksum of its generated
+	// content, so commands that rewrite files can detect hand edits.
+	Files map[string]string `yaml:"files,omitempty"`
+}
+
+// Template records a template pack and the answers to its prompts.
+type Template struct {
+	Source string            `yaml:"source"`           // Directory (relative to the project) or git URL
+	Commit string            `yaml:"commit,omitempty"` // Commit a git source was generated from
+	Values map[string]string `yaml:"values,omitempty"`
+}
+
+// Resource records a generated resource.
+type Resource struct {
+	Name   string `yaml:"name"`
+	Fields string `yaml:"fields,omitempty"` // As given to --fields
+}
+
+// HasLambda reports whether the project is deployed to AWS Lambda.
+func (m *Manifest) HasLambda() bool {
+	return m.Deploy == "lambda"
+}
+
+// Load reads the manifest from dir.
+func Load(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, FileName))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", FileName, err)
+	}
+
+	var m Manifest
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", FileName, err)
+	}
+
+	return &m, nil
+}
+
+// Marshal returns the YAML encoding of the manifest.
+func (m *Manifest) Marshal() ([]byte, error) {
+	data, err := yaml.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s: %w", FileName, err)
+	}
+	return data, nil
+}
+
+// Save writes the manifest to dir.
+func (m *Manifest) Save(dir string) error {
+	data, err := m.Marshal()
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, FileName), data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", FileName, err)
+	}
+
+	return nil
+}
+
+// Checksum returns the checksum recorded for generated content.
+func Checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// Unchanged reports whether content at path still matches what was generated.
+func (m *Manifest) Unchanged(path string, content []byte) bool {
+	sum, ok := m.Files[path]
+	return ok && sum == Checksum(content)
+}
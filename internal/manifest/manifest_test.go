@@ -0,0 +1,70 @@
+package manifest
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSaveLoad(t *testing.T) {
+	dir := t.TempDir()
+	m := &Manifest{
+		ProjectName:    "demo",
+		ModuleName:     "example.com/demo",
+		GoVersion:      "1.21",
+		GinbootVersion: "v1.14.2",
+		Database:       "postgres",
+		Storage:        "s3",
+		Deploy:         "lambda",
+		Telemetry:      true,
+		Arch:           "arm64",
+		Runtime:        "provided.al2023",
+		Template:       &Template{Source: "../templates", Values: map[string]string{"team": "payments"}},
+		Files:          map[string]string{"main.go": Checksum([]byte("package main\n"))},
+	}
+	if err := m.Save(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !reflect.DeepEqual(loaded, m) {
+		t.Errorf("Load() = %+v, want %+v", loaded, m)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	if _, err := Load(t.TempDir()); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Load() of an empty directory error = %v, want ErrNotFound", err)
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, FileName), []byte("project_name: [demo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(dir); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("Load() of an invalid manifest error = %v, want a parse error", err)
+	}
+}
+
+func TestUnchanged(t *testing.T) {
+	content := []byte("package main\n")
+	m := &Manifest{Files: map[string]string{"main.go": Checksum(content)}}
+
+	if !m.Unchanged("main.go", content) {
+		t.Error("Unchanged() = false for the generated content")
+	}
+	if m.Unchanged("main.go", []byte("package main // edited\n")) {
+		t.Error("Unchanged() = true for edited content")
+	}
+	if m.Unchanged("go.mod", content) {
+		t.Error("Unchanged() = true for a file without a checksum")
+	}
+	if Checksum(content) != Checksum([]byte("package main\n")) || Checksum(content) == Checksum(nil) {
+		t.Error("Checksum() is not a function of the content")
+	}
+}
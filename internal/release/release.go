@@ -0,0 +1,323 @@
+package release
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Source looks up the latest released version of a Go module.
+type Source interface {
+	// Latest returns the highest released version of module, e.g. "v1.14.2".
+	Latest(ctx context.Context, module string) (string, error)
+	// String describes the source for user-facing messages.
+	String() string
+}
+
+var httpClient = &http.Client{Timeout: 5 * time.Second}
+
+// GitHub reads the latest release from the GitHub releases API. The
+// repository is derived from a github.com module path.
+type GitHub struct {
+	BaseURL string // Defaults to https://api.github.com
+}
+
+func (s GitHub) String() string {
+	return "GitHub releases"
+}
+
+func (s GitHub) Latest(ctx context.Context, module string) (string, error) {
+	owner, repo, ok := githubRepo(module)
+	if !ok {
+		return "", fmt.Errorf("%s is not a github.com module", module)
+	}
+
+	base := strings.TrimSuffix(s.BaseURL, "/")
+	if base == "" {
+		base = "https://api.github.com"
+	}
+
+	body, err := get(ctx, fmt.Sprintf("%s/repos/%s/%s/releases/latest", base, owner, repo))
+	if err != nil {
+		return "", err
+	}
+
+	var release struct {
+		TagName string `json:"tag_name"`
+	}
+	if err := json.Unmarshal(body, &release); err != nil {
+		return "", fmt.Errorf("failed to parse release: %w", err)
+	}
+	if release.TagName == "" {
+		return "", fmt.Errorf("release has no tag")
+	}
+
+	return release.TagName, nil
+}
+
+// GoProxy reads the version list of a module proxy implementing the
+// GOPROXY protocol (`<module>/@v/list`).
+type GoProxy struct {
+	URL string
+}
+
+func (s GoProxy) String() string {
+	return "module proxy " + s.URL
+}
+
+func (s GoProxy) Latest(ctx context.Context, module string) (string, error) {
+	escaped, err := escapeModule(module)
+	if err != nil {
+		return "", err
+	}
+
+	body, err := get(ctx, strings.TrimSuffix(s.URL, "/")+"/"+escaped+"/@v/list")
+	if err != nil {
+		return "", err
+	}
+
+	return highest(string(body))
+}
+
+// Local reads versions from disk. Path is either a file listing one version
+// per line or a directory laid out like a GOPROXY (`<module>/@v/list`), so
+// the same directory can be used with GOPROXY=file://<path>.
+type Local struct {
+	Path string
+}
+
+func (s Local) String() string {
+	return "local releases at " + s.Path
+}
+
+func (s Local) Latest(ctx context.Context, module string) (string, error) {
+	path := s.Path
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		escaped, err := escapeModule(module)
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(path, filepath.FromSlash(escaped), "@v", "list")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	return highest(string(data))
+}
+
+// Parse returns the source described by a spec such as "github",
+// "goproxy:https://proxy.example.com" or "local:/srv/releases".
+func Parse(spec string) (Source, error) {
+	kind, arg, _ := strings.Cut(strings.TrimSpace(spec), ":")
+	return New(kind, arg)
+}
+
+// New returns the source of the given kind. location is the base URL for
+// github and goproxy sources and the file or directory for local sources.
+func New(kind, location string) (Source, error) {
+	switch kind {
+	case "", "github":
+		return GitHub{BaseURL: location}, nil
+	case "goproxy":
+		if location == "" {
+			return nil, fmt.Errorf("goproxy release source requires a URL")
+		}
+		return GoProxy{URL: location}, nil
+	case "local":
+		if location == "" {
+			return nil, fmt.Errorf("local release source requires a path")
+		}
+		return Local{Path: location}, nil
+	default:
+		return nil, fmt.Errorf("unknown release source '%s': must be one of github, goproxy, local", kind)
+	}
+}
+
+func get(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: unexpected status %s", url, resp.Status)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+func githubRepo(module string) (owner, repo string, ok bool) {
+	parts := strings.Split(module, "/")
+	if len(parts) < 3 || parts[0] != "github.com" {
+		return "", "", false
+	}
+	return parts[1], parts[2], true
+}
+
+// escapeModule applies the GOPROXY case encoding: upper-case letters become
+// '!' followed by the lower-case letter.
+func escapeModule(module string) (string, error) {
+	var b strings.Builder
+	for _, r := range module {
+		switch {
+		case r == '!':
+			return "", fmt.Errorf("invalid module path %s", module)
+		case r >= 'A' && r <= 'Z':
+			b.WriteByte('!')
+			b.WriteRune(r + ('a' - 'A'))
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String(), nil
+}
+
+// highest returns the highest semantic version in a newline-separated list.
+// Like `go get module@latest`, releases are preferred over pre-releases.
+func highest(list string) (string, error) {
+	var release, prerelease string
+	scanner := bufio.NewScanner(strings.NewReader(list))
+	for scanner.Scan() {
+		v := strings.TrimSpace(scanner.Text())
+		if v == "" || strings.HasPrefix(v, "#") {
+			continue
+		}
+		parsed, ok := parseSemver(v)
+		if !ok {
+			continue
+		}
+		best := &release
+		if parsed.pre != "" {
+			best = &prerelease
+		}
+		if *best == "" || Compare(v, *best) > 0 {
+			*best = v
+		}
+	}
+
+	switch {
+	case release != "":
+		return release, nil
+	case prerelease != "":
+		return prerelease, nil
+	}
+	return "", fmt.Errorf("no versions found")
+}
+
+type semver struct {
+	major, minor, patch int
+	pre                 string
+}
+
+func parseSemver(v string) (semver, bool) {
+	if !strings.HasPrefix(v, "v") {
+		return semver{}, false
+	}
+	v = strings.TrimPrefix(v, "v")
+	if i := strings.Index(v, "+"); i >= 0 {
+		v = v[:i]
+	}
+
+	var s semver
+	core := v
+	if i := strings.Index(v, "-"); i >= 0 {
+		core, s.pre = v[:i], v[i+1:]
+	}
+
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return semver{}, false
+	}
+	nums := make([]int, 3)
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return semver{}, false
+		}
+		nums[i] = n
+	}
+	s.major, s.minor, s.patch = nums[0], nums[1], nums[2]
+
+	return s, true
+}
+
+// Compare compares two semantic versions, returning -1, 0 or +1. Invalid
+// versions sort before valid ones.
+func Compare(a, b string) int {
+	va, okA := parseSemver(a)
+	vb, okB := parseSemver(b)
+	switch {
+	case !okA && !okB:
+		return 0
+	case !okA:
+		return -1
+	case !okB:
+		return 1
+	}
+
+	for _, d := range []int{va.major - vb.major, va.minor - vb.minor, va.patch - vb.patch} {
+		if d != 0 {
+			return sign(d)
+		}
+	}
+
+	switch {
+	case va.pre == vb.pre:
+		return 0
+	case va.pre == "":
+		return 1
+	case vb.pre == "":
+		return -1
+	}
+	return comparePrerelease(va.pre, vb.pre)
+}
+
+func comparePrerelease(a, b string) int {
+	pa, pb := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(pa) && i < len(pb); i++ {
+		na, errA := strconv.Atoi(pa[i])
+		nb, errB := strconv.Atoi(pb[i])
+		switch {
+		case errA == nil && errB == nil:
+			if na != nb {
+				return sign(na - nb)
+			}
+		case errA == nil:
+			return -1
+		case errB == nil:
+			return 1
+		default:
+			if c := strings.Compare(pa[i], pb[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return sign(len(pa) - len(pb))
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,140 @@
+package release
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGitHubLatest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/klass-lk/ginboot/releases/latest" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"tag_name":"v1.20.0"}`))
+	}))
+	defer srv.Close()
+
+	got, err := GitHub{BaseURL: srv.URL}.Latest(context.Background(), "github.com/klass-lk/ginboot")
+	if err != nil {
+		t.Fatalf("Latest() error = %v", err)
+	}
+	if got != "v1.20.0" {
+		t.Errorf("Latest() = %q, want %q", got, "v1.20.0")
+	}
+}
+
+func TestGoProxyLatest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/github.com/!klass-lk/!gin!boot/@v/list" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("v1.9.0\nv1.10.0\nv1.11.0-rc.1\nv1.2.3\n"))
+	}))
+	defer srv.Close()
+
+	got, err := GoProxy{URL: srv.URL}.Latest(context.Background(), "github.com/Klass-lk/GinBoot")
+	if err != nil {
+		t.Fatalf("Latest() error = %v", err)
+	}
+	if got != "v1.10.0" {
+		t.Errorf("Latest() = %q, want %q", got, "v1.10.0")
+	}
+}
+
+func TestGoProxyLatestNotFound(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	if _, err := (GoProxy{URL: srv.URL}).Latest(context.Background(), "github.com/klass-lk/ginboot"); err == nil {
+		t.Fatal("Latest() error = nil, want error")
+	}
+}
+
+func TestLocalLatest(t *testing.T) {
+	dir := t.TempDir()
+	listDir := filepath.Join(dir, "github.com", "klass-lk", "ginboot", "@v")
+	if err := os.MkdirAll(listDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(listDir, "list"), []byte("v1.1.0\nv1.3.0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, "versions.txt")
+	if err := os.WriteFile(file, []byte("# pinned releases\nv2.0.0-beta.1\nv1.4.0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{name: "proxy directory", path: dir, want: "v1.3.0"},
+		{name: "version file", path: file, want: "v1.4.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Local{Path: tt.path}.Latest(context.Background(), "github.com/klass-lk/ginboot")
+			if err != nil {
+				t.Fatalf("Latest() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Latest() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    Source
+		wantErr bool
+	}{
+		{spec: "", want: GitHub{}},
+		{spec: "github", want: GitHub{}},
+		{spec: "goproxy:https://proxy.example.com", want: GoProxy{URL: "https://proxy.example.com"}},
+		{spec: "local:/srv/releases", want: Local{Path: "/srv/releases"}},
+		{spec: "goproxy", wantErr: true},
+		{spec: "s3:bucket", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := Parse(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("Parse(%q) = %#v, want %#v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"v1.10.0", "v1.9.0", 1},
+		{"v1.0.0", "v1.0.0", 0},
+		{"v1.0.0-rc.1", "v1.0.0", -1},
+		{"v1.0.0-rc.2", "v1.0.0-rc.10", -1},
+		{"v1.0.0-alpha", "v1.0.0-beta", -1},
+		{"invalid", "v0.0.1", -1},
+	}
+
+	for _, tt := range tests {
+		if got := Compare(tt.a, tt.b); got != tt.want {
+			t.Errorf("Compare(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
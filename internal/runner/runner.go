@@ -0,0 +1,151 @@
+// Package runner runs the external tools the CLI drives, such as sam, aws
+// and go. Commands go through a Runner so that tests can record them with a
+// Recorder instead of starting processes.
+package runner
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Command is an invocation of an external tool.
+type Command struct {
+	Name string
+	Args []string
+	// Env is added to the environment of the current process.
+	Env []string
+	Dir string
+
+	Stdin  io.Reader
+	Stdout io.Writer // Discarded when nil
+	Stderr io.Writer // Discarded when nil
+
+	// GracePeriod, if set, makes cancelling the context interrupt the
+	// process instead of killing it; it is killed if it is still running
+	// after GracePeriod.
+	GracePeriod time.Duration
+}
+
+// String returns the command line, e.g. "sam build".
+func (c Command) String() string {
+	return strings.Join(append([]string{c.Name}, c.Args...), " ")
+}
+
+// Runner runs commands.
+type Runner interface {
+	// LookPath reports where a tool is installed, like exec.LookPath.
+	LookPath(name string) (string, error)
+	// Run runs the command and waits for it to finish.
+	Run(ctx context.Context, c Command) error
+}
+
+// Exec runs commands as processes.
+type Exec struct{}
+
+func (Exec) LookPath(name string) (string, error) {
+	return exec.LookPath(name)
+}
+
+func (Exec) Run(ctx context.Context, c Command) error {
+	cmd := exec.CommandContext(ctx, c.Name, c.Args...)
+	cmd.Dir = c.Dir
+	cmd.Stdin = c.Stdin
+	cmd.Stdout = c.Stdout
+	cmd.Stderr = c.Stderr
+	if len(c.Env) > 0 {
+		cmd.Env = append(os.Environ(), c.Env...)
+	}
+	if c.GracePeriod > 0 {
+		cmd.Cancel = func() error { return cmd.Process.Signal(os.Interrupt) }
+		cmd.WaitDelay = c.GracePeriod
+	}
+	return cmd.Run()
+}
+
+// Output runs the command and returns its stdout. Unless c.Stderr is set,
+// stderr is captured and included in the error.
+func Output(ctx context.Context, r Runner, c Command) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	c.Stdout = &stdout
+	if c.Stderr == nil {
+		c.Stderr = &stderr
+	}
+	if err := r.Run(ctx, c); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return stdout.Bytes(), fmt.Errorf("%w\n%s", err, msg)
+		}
+		return stdout.Bytes(), err
+	}
+	return stdout.Bytes(), nil
+}
+
+// Recorder is a Runner for tests. It records every command instead of
+// running it.
+type Recorder struct {
+	// Handle, if set, plays the part of the tool: it may write to c.Stdout
+	// and c.Stderr, and its error is returned by Run.
+	Handle func(c Command) error
+	// HandleContext is used instead of Handle when set, for tools that must
+	// see ctx, such as long-running processes that are interrupted.
+	HandleContext func(ctx context.Context, c Command) error
+	// Missing lists the tools LookPath reports as not installed.
+	Missing []string
+
+	mu       sync.Mutex
+	commands []Command
+}
+
+func (r *Recorder) LookPath(name string) (string, error) {
+	for _, missing := range r.Missing {
+		if missing == name {
+			return "", &exec.Error{Name: name, Err: exec.ErrNotFound}
+		}
+	}
+	return "/usr/bin/" + name, nil
+}
+
+func (r *Recorder) Run(ctx context.Context, c Command) error {
+	r.mu.Lock()
+	r.commands = append(r.commands, c)
+	r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if r.Handle == nil && r.HandleContext == nil {
+		return nil
+	}
+	if c.Stdout == nil {
+		c.Stdout = io.Discard
+	}
+	if c.Stderr == nil {
+		c.Stderr = io.Discard
+	}
+	if r.HandleContext != nil {
+		return r.HandleContext(ctx, c)
+	}
+	return r.Handle(c)
+}
+
+// Commands returns the commands run so far.
+func (r *Recorder) Commands() []Command {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Command(nil), r.commands...)
+}
+
+// Lines returns the command lines run so far, see Command.String.
+func (r *Recorder) Lines() []string {
+	var lines []string
+	for _, c := range r.Commands() {
+		lines = append(lines, c.String())
+	}
+	return lines
+}
@@ -0,0 +1,99 @@
+package runner
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExecOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	out, err := Output(context.Background(), Exec{}, Command{Name: "sh", Args: []string{"-c", "echo $GREETING"}, Env: []string{"GREETING=hello"}})
+	if err != nil {
+		t.Fatalf("Output() error = %v", err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "hello" {
+		t.Errorf("Output() = %q, want %q", got, "hello")
+	}
+
+	_, err = Output(context.Background(), Exec{}, Command{Name: "sh", Args: []string{"-c", "echo broken >&2; exit 3"}})
+	if err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("Output() error = %v, want it to include stderr", err)
+	}
+}
+
+func TestExecGracePeriod(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	pr, pw := io.Pipe()
+	done := make(chan error)
+	go func() {
+		err := Exec{}.Run(ctx, Command{
+			Name:        "sh",
+			Args:        []string{"-c", "trap 'echo stopping; exit 0' INT; echo started; while :; do sleep 0.05; done"},
+			Stdout:      pw,
+			GracePeriod: 5 * time.Second,
+		})
+		pw.Close()
+		done <- err
+	}()
+
+	lines := bufio.NewScanner(pr)
+	lines.Scan()
+	cancel()
+	var after []string
+	for lines.Scan() {
+		after = append(after, lines.Text())
+	}
+	<-done
+	if !reflect.DeepEqual(after, []string{"stopping"}) {
+		t.Errorf("output after cancelling = %q, want the process to handle the interrupt", after)
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	rec := &Recorder{
+		Missing: []string{"aws"},
+		Handle: func(c Command) error {
+			if c.Args[0] == "deploy" {
+				return errors.New("exit status 1")
+			}
+			fmt.Fprint(c.Stdout, "built")
+			return nil
+		},
+	}
+
+	if _, err := rec.LookPath("sam"); err != nil {
+		t.Errorf("LookPath(sam) error = %v", err)
+	}
+	if _, err := rec.LookPath("aws"); !errors.Is(err, exec.ErrNotFound) {
+		t.Errorf("LookPath(aws) error = %v, want exec.ErrNotFound", err)
+	}
+
+	out, err := Output(context.Background(), rec, Command{Name: "sam", Args: []string{"build"}})
+	if err != nil || string(out) != "built" {
+		t.Errorf("Output() = %q, %v, want %q", out, err, "built")
+	}
+	if err := rec.Run(context.Background(), Command{Name: "sam", Args: []string{"deploy", "--stack-name", "demo"}}); err == nil {
+		t.Error("Run() succeeded, want the handler's error")
+	}
+
+	want := []string{"sam build", "sam deploy --stack-name demo"}
+	if got := rec.Lines(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Lines() = %q, want %q", got, want)
+	}
+}
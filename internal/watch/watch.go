@@ -0,0 +1,146 @@
+// Package watch detects changes to the files of a directory tree. It polls
+// instead of relying on file system notifications, so it behaves the same on
+// every platform, including network file systems and container mounts.
+package watch
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Snapshot is the state of the watched files, keyed by slash-separated path
+// relative to the root.
+type Snapshot map[string]FileState
+
+// FileState is what a change to a file is detected by.
+type FileState struct {
+	Size    int64
+	ModTime time.Time
+}
+
+// Watcher watches the files below Root.
+type Watcher struct {
+	Root string
+	// Match reports whether a file is watched; all files are when nil.
+	Match func(path string) bool
+	// Skip reports whether a directory is skipped; none are when nil.
+	Skip func(dir string) bool
+
+	// Interval is the time between scans; Debounce how long the files must
+	// be left alone before their changes are reported, so that saving
+	// several files reports them together.
+	Interval time.Duration
+	Debounce time.Duration
+}
+
+// Scan returns the current state of the watched files.
+func (w *Watcher) Scan() (Snapshot, error) {
+	snapshot := Snapshot{}
+	err := filepath.WalkDir(w.Root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Files may disappear while the tree is walked
+			if path != w.Root && errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		rel, err := filepath.Rel(w.Root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if d.IsDir() {
+			if rel != "." && w.Skip != nil && w.Skip(rel) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if w.Match != nil && !w.Match(rel) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		snapshot[rel] = FileState{Size: info.Size(), ModTime: info.ModTime()}
+		return nil
+	})
+	return snapshot, err
+}
+
+// Changed returns the sorted paths added, removed or modified between two
+// snapshots.
+func Changed(before, after Snapshot) []string {
+	var paths []string
+	for path, state := range after {
+		if old, ok := before[path]; !ok || old.Size != state.Size || !old.ModTime.Equal(state.ModTime) {
+			paths = append(paths, path)
+		}
+	}
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Watch scans the files every Interval and sends the changed paths on
+// changes once they were left alone for Debounce. It returns when ctx is
+// done, or with the error of a failed scan.
+func (w *Watcher) Watch(ctx context.Context, changes chan<- []string) error {
+	last, err := w.Scan()
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	pending := map[string]bool{}
+	var changedAt time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		current, err := w.Scan()
+		if err != nil {
+			return err
+		}
+		if paths := Changed(last, current); len(paths) > 0 {
+			for _, path := range paths {
+				pending[path] = true
+			}
+			changedAt = time.Now()
+		}
+		last = current
+
+		if len(pending) == 0 || time.Since(changedAt) < w.Debounce {
+			continue
+		}
+		paths := make([]string, 0, len(pending))
+		for path := range pending {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		pending = map[string]bool{}
+
+		select {
+		case changes <- paths:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
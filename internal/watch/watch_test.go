@@ -0,0 +1,92 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestScan(t *testing.T) {
+	root := t.TempDir()
+	for _, path := range []string{"main.go", "README.md", "internal/model/user.go", ".git/HEAD", "vendor/x/x.go"} {
+		write(t, filepath.Join(root, path), "x")
+	}
+
+	w := &Watcher{
+		Root:  root,
+		Match: func(path string) bool { return strings.HasSuffix(path, ".go") },
+		Skip:  func(dir string) bool { return dir == ".git" || dir == "vendor" },
+	}
+	snapshot, err := w.Scan()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every file of a snapshot is new compared with an empty one
+	if got, want := Changed(nil, snapshot), []string{"internal/model/user.go", "main.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("watched %q, want %q", got, want)
+	}
+}
+
+func TestChanged(t *testing.T) {
+	now := time.Now()
+	before := Snapshot{
+		"a.go": {Size: 1, ModTime: now},
+		"b.go": {Size: 1, ModTime: now},
+		"c.go": {Size: 1, ModTime: now},
+	}
+	after := Snapshot{
+		"a.go": {Size: 1, ModTime: now},
+		"b.go": {Size: 1, ModTime: now.Add(time.Second)},
+		"d.go": {Size: 1, ModTime: now},
+	}
+	if got, want := Changed(before, after), []string{"b.go", "c.go", "d.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Changed() = %q, want %q", got, want)
+	}
+}
+
+func TestWatchDebounces(t *testing.T) {
+	root := t.TempDir()
+	write(t, filepath.Join(root, "main.go"), "package main")
+
+	w := &Watcher{Root: root, Interval: 10 * time.Millisecond, Debounce: 100 * time.Millisecond}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan []string)
+	done := make(chan error)
+	go func() { done <- w.Watch(ctx, changes) }()
+
+	// Let the first scan happen, then save two files in quick succession
+	time.Sleep(50 * time.Millisecond)
+	write(t, filepath.Join(root, "main.go"), "package main // edited")
+	time.Sleep(30 * time.Millisecond)
+	write(t, filepath.Join(root, "go.mod"), "module demo")
+
+	select {
+	case paths := <-changes:
+		if want := []string{"go.mod", "main.go"}; !reflect.DeepEqual(paths, want) {
+			t.Errorf("changes = %q, want %q", paths, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no changes reported")
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Watch() error = %v", err)
+	}
+}
+
+func write(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,320 @@
+// Package scaffold generates Ginboot projects. It is the stable Go API behind
+// `ginboot new`, for tools that drive scaffolding programmatically:
+//
+//	result, err := scaffold.Generate(ctx, scaffold.Options{
+//		ProjectName: "orders",
+//		ModuleName:  "github.com/acme/orders",
+//		Database:    scaffold.DatabasePostgres,
+//		Storage:     scaffold.StorageNone,
+//		Deploy:      scaffold.DeployLambda,
+//	})
+package scaffold
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/klass-lk/ginboot-cli/internal/config"
+	"github.com/klass-lk/ginboot-cli/internal/generator"
+)
+
+// Supported databases.
+const (
+	DatabaseNone     = "none"
+	DatabaseMongoDB  = "mongodb"
+	DatabasePostgres = "postgres"
+	DatabaseMySQL    = "mysql"
+	DatabaseDynamoDB = "dynamodb"
+)
+
+// Supported file storage backends.
+const (
+	StorageNone = "none"
+	StorageS3   = "s3"
+)
+
+// Supported deployment targets.
+const (
+	DeployHTTP   = "http"
+	DeployLambda = "lambda"
+)
+
+// Supported Lambda architectures.
+const (
+	ArchX86_64 = "x86_64"
+	ArchARM64  = "arm64"
+)
+
+// Supported Lambda runtimes.
+const (
+	RuntimeProvidedAL2    = "provided.al2"
+	RuntimeProvidedAL2023 = "provided.al2023"
+)
+
+// DefaultGoVersion is the go directive of generated projects when Options
+// doesn't set one.
+const DefaultGoVersion = "1.21"
+
+var (
+	// Databases lists the supported Options.Database values.
+	Databases = []string{DatabaseNone, DatabaseMongoDB, DatabasePostgres, DatabaseMySQL, DatabaseDynamoDB}
+	// Storages lists the supported Options.Storage values.
+	Storages = []string{StorageNone, StorageS3}
+	// Deploys lists the supported Options.Deploy values.
+	Deploys = []string{DeployHTTP, DeployLambda}
+	// Archs lists the supported Options.Arch values.
+	Archs = []string{ArchX86_64, ArchARM64}
+	// Runtimes lists the supported Options.Runtime values.
+	Runtimes = []string{RuntimeProvidedAL2, RuntimeProvidedAL2023}
+)
+
+// File is a generated file, relative to the project root.
+type File = generator.File
+
+// Writer receives the generated files. DirWriter, MemoryWriter, ZipWriter
+// and TarGzWriter are provided.
+type Writer = generator.Writer
+
+// Writer implementations; see the generator documentation of each.
+type (
+	DirWriter    = generator.DirWriter
+	MemoryWriter = generator.MemoryWriter
+	ZipWriter    = generator.ZipWriter
+	TarGzWriter  = generator.TarGzWriter
+)
+
+// Options describes the project to generate.
+type Options struct {
+	ProjectName string // Letters and digits only
+	ModuleName  string // Defaults to ProjectName
+	GoVersion   string // Defaults to DefaultGoVersion
+	Database    string // One of Databases
+	Storage     string // One of Storages
+	Deploy      string // One of Deploys
+	Telemetry   bool
+
+	// Arch and Runtime configure lambda projects: one of Archs (default
+	// x86_64) and one of Runtimes (default provided.al2).
+	Arch    string
+	Runtime string
+
+	// GinbootVersion pins the Ginboot framework version. When empty it is
+	// resolved like `ginboot new` does: $GINBOOT_VERSION, the cached or
+	// latest release from the configured release source, or the built-in
+	// default when Offline.
+	GinbootVersion string
+	Offline        bool
+
+	// Template is an optional template pack (directory or git URL) and
+	// Values the answers to its prompts; unanswered prompts get their
+	// defaults.
+	Template string
+	Values   map[string]string
+
+	// Dir is the project directory; it defaults to ProjectName. It is not
+	// used when Output is set.
+	Dir string
+	// Output receives the files instead of Dir, e.g. a MemoryWriter or a
+	// ZipWriter.
+	Output Writer
+	// Force overwrites existing files in Dir that differ from the
+	// generated ones.
+	Force bool
+
+	// Progress, if set, is called as generation advances.
+	Progress func(Event)
+}
+
+// Stage is a step of project generation.
+type Stage string
+
+const (
+	StageResolve Stage = "resolve" // Resolving the Ginboot version and template pack
+	StageRender  Stage = "render"  // A file was rendered
+	StageWrite   Stage = "write"   // Files are being written
+	StageDone    Stage = "done"    // Every file was written
+)
+
+// Event reports generation progress. Done and Total count files during
+// StageRender.
+type Event struct {
+	Stage   Stage
+	Message string
+	Path    string
+	Done    int
+	Total   int
+}
+
+// Result describes a generated project.
+type Result struct {
+	Dir            string // Empty when Options.Output was used
+	GinbootVersion string
+	Files          []string
+}
+
+// ValidationError reports an unsupported option value.
+type ValidationError struct {
+	Field   string // e.g. "database type"
+	Value   string
+	Allowed []string // Supported values, if the field has a fixed set
+	Reason  string   // Set when Allowed is empty
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Allowed) > 0 {
+		return fmt.Sprintf("invalid %s '%s': must be one of %s", e.Field, e.Value, strings.Join(e.Allowed, ", "))
+	}
+	return fmt.Sprintf("invalid %s '%s': %s", e.Field, e.Value, e.Reason)
+}
+
+// OverwriteError is returned by Generate when files in the project
+// directory would be overwritten and Options.Force is not set.
+type OverwriteError struct {
+	Paths []string
+}
+
+func (e *OverwriteError) Error() string {
+	return fmt.Sprintf("%d existing file(s) would be overwritten: %s", len(e.Paths), strings.Join(e.Paths, ", "))
+}
+
+var projectNamePattern = regexp.MustCompile("^[a-zA-Z0-9]+$")
+
+// ValidateProjectName checks that name contains only letters and digits.
+func ValidateProjectName(name string) error {
+	if !projectNamePattern.MatchString(name) {
+		return &ValidationError{Field: "project name", Value: name, Reason: "must contain only letters and numbers"}
+	}
+	return nil
+}
+
+// Validate checks the options. The returned error joins a *ValidationError
+// for every invalid field; use errors.As to inspect them.
+func (o Options) Validate() error {
+	var errs []error
+	if err := ValidateProjectName(o.ProjectName); err != nil {
+		errs = append(errs, err)
+	}
+	if !contains(Databases, o.Database) {
+		errs = append(errs, &ValidationError{Field: "database type", Value: o.Database, Allowed: Databases})
+	}
+	if !contains(Storages, o.Storage) {
+		errs = append(errs, &ValidationError{Field: "storage type", Value: o.Storage, Allowed: Storages})
+	}
+	if !contains(Deploys, o.Deploy) {
+		errs = append(errs, &ValidationError{Field: "deployment type", Value: o.Deploy, Allowed: Deploys})
+	}
+	if o.Arch != "" && !contains(Archs, o.Arch) {
+		errs = append(errs, &ValidationError{Field: "architecture", Value: o.Arch, Allowed: Archs})
+	}
+	if o.Runtime != "" && !contains(Runtimes, o.Runtime) {
+		errs = append(errs, &ValidationError{Field: "runtime", Value: o.Runtime, Allowed: Runtimes})
+	}
+	return errors.Join(errs...)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Generate validates the options and generates the project. Either every
+// file is written or, on error, nothing is.
+func Generate(ctx context.Context, opts Options) (*Result, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if opts.ModuleName == "" {
+		opts.ModuleName = opts.ProjectName
+	}
+	if opts.GoVersion == "" {
+		opts.GoVersion = DefaultGoVersion
+	}
+	if opts.Dir == "" {
+		opts.Dir = opts.ProjectName
+	}
+	progress := opts.Progress
+	if progress == nil {
+		progress = func(Event) {}
+	}
+
+	progress(Event{Stage: StageResolve, Message: "resolving Ginboot version"})
+	version := opts.GinbootVersion
+	if version == "" {
+		userConfig, err := config.Load()
+		if err != nil {
+			return nil, err
+		}
+		source, err := userConfig.Source()
+		if err != nil {
+			return nil, err
+		}
+		version = generator.ResolveGinbootVersion(ctx, generator.VersionOptions{Offline: opts.Offline, Source: source}).Version
+	}
+
+	gen := generator.NewProjectGenerator(filepath.Clean(opts.Dir), opts.ProjectName, opts.ModuleName, opts.GoVersion, opts.Database, opts.Storage, opts.Deploy, opts.Telemetry)
+	gen.GinbootVersion = version
+	gen.Arch = opts.Arch
+	gen.Runtime = opts.Runtime
+	gen.Output = opts.Output
+	gen.Progress = func(path string, done, total int) {
+		progress(Event{Stage: StageRender, Path: path, Done: done, Total: total})
+	}
+	if opts.Template != "" {
+		progress(Event{Stage: StageResolve, Message: "loading template pack " + opts.Template})
+		pack, err := generator.LoadTemplatePack(ctx, opts.Template, opts.Offline)
+		if err != nil {
+			return nil, err
+		}
+		gen.Pack = pack
+		gen.Values = pack.Values(opts.Values)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	files, err := gen.ProjectFiles()
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, len(files))
+	for i, file := range files {
+		paths[i] = file.Path
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result := &Result{GinbootVersion: version, Files: paths}
+	if opts.Output == nil {
+		result.Dir = opts.Dir
+		if !opts.Force {
+			overwrites, err := gen.Overwrites(files)
+			if err != nil {
+				return nil, err
+			}
+			if len(overwrites) > 0 {
+				overwritten := make([]string, len(overwrites))
+				for i, change := range overwrites {
+					overwritten[i] = change.File.Path
+				}
+				return nil, &OverwriteError{Paths: overwritten}
+			}
+		}
+	}
+
+	progress(Event{Stage: StageWrite, Message: fmt.Sprintf("writing %d files", len(files)), Total: len(files)})
+	if err := gen.Write(files); err != nil {
+		return nil, err
+	}
+	progress(Event{Stage: StageDone, Done: len(files), Total: len(files)})
+
+	return result, nil
+}
@@ -0,0 +1,131 @@
+package scaffold
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klass-lk/ginboot-cli/internal/config"
+	"github.com/klass-lk/ginboot-cli/internal/generator"
+)
+
+func TestValidate(t *testing.T) {
+	err := Options{ProjectName: "my-app", Database: "oracle", Storage: StorageS3, Deploy: "k8s", Arch: "arm"}.Validate()
+
+	var fields []string
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var verr *ValidationError
+		if !errors.As(e, &verr) {
+			t.Fatalf("error %v is not a *ValidationError", e)
+		}
+		fields = append(fields, verr.Field)
+	}
+
+	want := []string{"project name", "database type", "deployment type", "architecture"}
+	if len(fields) != len(want) {
+		t.Fatalf("invalid fields = %v, want %v", fields, want)
+	}
+	for i := range want {
+		if fields[i] != want[i] {
+			t.Errorf("invalid fields = %v, want %v", fields, want)
+		}
+	}
+}
+
+func TestGenerateToMemory(t *testing.T) {
+	var out MemoryWriter
+	var stages []Stage
+	var rendered []Event
+	result, err := Generate(context.Background(), Options{
+		ProjectName:    "orders",
+		ModuleName:     "example.com/orders",
+		Database:       DatabasePostgres,
+		Storage:        StorageNone,
+		Deploy:         DeployLambda,
+		GinbootVersion: "v1.14.2",
+		Dir:            t.TempDir(),
+		Output:         &out,
+		Progress: func(e Event) {
+			stages = append(stages, e.Stage)
+			if e.Stage == StageRender {
+				rendered = append(rendered, e)
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	if len(out.Files) != len(result.Files) {
+		t.Errorf("wrote %d files, result lists %d", len(out.Files), len(result.Files))
+	}
+	for _, path := range []string{"main.go", "template.yaml", "ginboot.yaml"} {
+		if _, ok := out.Files[path]; !ok {
+			t.Errorf("%s was not generated", path)
+		}
+	}
+	if len(stages) == 0 || stages[len(stages)-1] != StageDone {
+		t.Errorf("last progress stage = %v, want %s", stages, StageDone)
+	}
+	if len(rendered) != len(result.Files) {
+		t.Fatalf("%d render events for %d files", len(rendered), len(result.Files))
+	}
+	for i, e := range rendered {
+		if e.Path != result.Files[i] || e.Done != i+1 || e.Total != len(result.Files) {
+			t.Errorf("render event %d = %+v, want %s (%d/%d)", i, e, result.Files[i], i+1, len(result.Files))
+		}
+	}
+}
+
+func TestGenerateRefusesOverwrite(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := Generate(context.Background(), Options{
+		ProjectName:    "orders",
+		Database:       DatabaseNone,
+		Storage:        StorageNone,
+		Deploy:         DeployHTTP,
+		GinbootVersion: "v1.14.2",
+		Dir:            dir,
+	})
+
+	var overwrite *OverwriteError
+	if !errors.As(err, &overwrite) {
+		t.Fatalf("Generate() error = %v, want *OverwriteError", err)
+	}
+	if len(overwrite.Paths) != 1 || overwrite.Paths[0] != "main.go" {
+		t.Errorf("Paths = %v, want [main.go]", overwrite.Paths)
+	}
+}
+
+func TestGenerateReleaseSource(t *testing.T) {
+	releases := filepath.Join(t.TempDir(), "releases")
+	if err := os.WriteFile(releases, []byte("v1.14.2\nv1.19.1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(config.ReleaseSourceEnv, "local:"+releases)
+	t.Setenv(generator.GinbootVersionEnv, "")
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	result, err := Generate(context.Background(), Options{
+		ProjectName: "orders",
+		ModuleName:  "example.com/orders",
+		Database:    DatabaseNone,
+		Storage:     StorageNone,
+		Deploy:      DeployHTTP,
+		Dir:         t.TempDir(),
+		Output:      &MemoryWriter{},
+	})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if result.GinbootVersion != "v1.19.1" {
+		t.Errorf("GinbootVersion = %q, want v1.19.1 from the configured release source", result.GinbootVersion)
+	}
+}